@@ -0,0 +1,438 @@
+package toolindex
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/jonwraymond/toolmodel"
+)
+
+// Deprecate marks a tool as deprecated. Deprecated tools still resolve via
+// GetTool and appear in search, but the default searcher ranks them below
+// current tools with the same relevance. replacementID is optional; when set
+// it must refer to a different registered tool.
+func (idx *InMemoryIndex) Deprecate(toolID, replacementID, reason string) error {
+	if replacementID == toolID {
+		return fmt.Errorf("%w: tool %q cannot replace itself", ErrInvalidTool, toolID)
+	}
+
+	idx.mu.Lock()
+	record, exists := idx.tools[toolID]
+	if !exists {
+		idx.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrNotFound, toolID)
+	}
+	if replacementID != "" {
+		if _, ok := idx.tools[replacementID]; !ok {
+			idx.mu.Unlock()
+			return fmt.Errorf("%w: replacement %s", ErrNotFound, replacementID)
+		}
+	}
+
+	record.deprecation = &deprecation{replacedBy: replacementID, reason: reason}
+	record.updatedAt = idx.now()
+	idx.refreshRecordDerived(record)
+	idx.rehashLocked(record)
+
+	idx.updateSearchDocsLocked(toolID)
+	version := idx.indexVersion
+	listeners := idx.snapshotListenersLocked()
+	idx.mu.Unlock()
+
+	notifyListeners(listeners, ChangeEvent{
+		Type:    ChangeUpdated,
+		ToolID:  toolID,
+		Version: version,
+	})
+	return nil
+}
+
+// MergeOptions configures MergeTool.
+type MergeOptions struct {
+	// KeepAlias makes the source ID keep resolving to the target through
+	// GetTool, GetToolFuzzy, ResolveTool, GetSummary, GetAllBackends,
+	// GetBackendsPage, GetBackendsRanked, HasBackend, LastSeen, and
+	// UnregisterBackend.
+	// Registering a tool under the source ID later replaces the alias.
+	KeepAlias bool
+}
+
+// MergeTool folds the tool fromID into toID: backends missing from the target
+// are moved over, tags are unioned, and the source tool is removed. The two
+// tools must have identical input and output schemas, since callers of the
+// target may be routed to any of the moved backends; otherwise MergeTool
+// returns ErrInvalidTool. Listeners receive ChangeToolRemoved for fromID and
+// ChangeUpdated for toID.
+func (idx *InMemoryIndex) MergeTool(fromID, toID string, opts ...MergeOptions) error {
+	var opt MergeOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if fromID == toID {
+		return fmt.Errorf("%w: cannot merge tool %q into itself", ErrInvalidTool, fromID)
+	}
+
+	idx.mu.Lock()
+	from, ok := idx.tools[fromID]
+	if !ok {
+		idx.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrNotFound, fromID)
+	}
+	to, ok := idx.tools[toID]
+	if !ok {
+		idx.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrNotFound, toID)
+	}
+	if !jsonEqual(from.tool.InputSchema, to.tool.InputSchema) || !jsonEqual(from.tool.OutputSchema, to.tool.OutputSchema) {
+		idx.mu.Unlock()
+		return fmt.Errorf("%w: tools %q and %q have different schemas", ErrInvalidTool, fromID, toID)
+	}
+
+	for _, backend := range from.backends {
+		key := backendIdentity(backend)
+		if _, exists := to.backendKeys[key]; exists {
+			continue
+		}
+		to.backendKeys[key] = len(to.backends)
+		to.backends = append(to.backends, backend)
+	}
+	var droppedTags []string
+	for _, tag := range from.normalizedTags {
+		if slices.Contains(to.normalizedTags, tag) {
+			continue
+		}
+		if !idx.tagRoomLocked(to) {
+			droppedTags = append(droppedTags, tag)
+			continue
+		}
+		to.tool.Tags = append(slices.Clone(to.tool.Tags), tag)
+		to.normalizedTags = append(slices.Clone(to.normalizedTags), tag)
+	}
+	idx.refreshRecordDerived(to)
+	idx.rehashLocked(to)
+	idx.removeRecordLocked(fromID, from)
+
+	// Aliases pointing at the source now point at the target.
+	for alias, target := range idx.aliases {
+		if target == fromID {
+			idx.aliases[alias] = toID
+		}
+	}
+	if opt.KeepAlias {
+		if idx.aliases == nil {
+			idx.aliases = make(map[string]string)
+		}
+		idx.aliases[fromID] = toID
+	}
+
+	idx.updateSearchDocsLocked(fromID, toID)
+	to.backendsVersion = idx.indexVersion
+	to.updatedAt = idx.now()
+	version := idx.indexVersion
+	listeners := idx.snapshotListenersLocked()
+	idx.mu.Unlock()
+
+	idx.reportTruncatedTags(toID, droppedTags)
+	notifyListeners(listeners, ChangeEvent{Type: ChangeToolRemoved, ToolID: fromID, Version: version})
+	notifyListeners(listeners, ChangeEvent{Type: ChangeUpdated, ToolID: toID, Version: version})
+	return nil
+}
+
+// TagNamespace adds tag to every tool in namespace and returns how many tools
+// gained the tag. The tag is normalized with toolmodel.NormalizeTags.
+// Tags added this way are replaced if the tool is later re-registered. Tools
+// already at IndexOptions.MaxTagsPerTool do not gain the tag.
+func (idx *InMemoryIndex) TagNamespace(namespace, tag string) (int, error) {
+	normalized, err := normalizeSingleTag(tag)
+	if err != nil {
+		return 0, err
+	}
+
+	return idx.applyTag(normalized, func() []string {
+		var ids []string
+		for id, record := range idx.tools {
+			if record.tool.Namespace == namespace {
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	})
+}
+
+// DeleteNamespace removes every tool in namespace together with all of its
+// backends and returns how many tools were removed. The removal is atomic:
+// concurrent readers see either all of the namespace or none of it.
+// Listeners receive one ChangeToolRemoved event per tool, in ID order.
+func (idx *InMemoryIndex) DeleteNamespace(namespace string) (int, error) {
+	idx.mu.Lock()
+	var ids []string
+	for id, record := range idx.tools {
+		if record.tool.Namespace == namespace {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		idx.mu.Unlock()
+		return 0, fmt.Errorf("%w: namespace %s", ErrNotFound, namespace)
+	}
+
+	sort.Strings(ids)
+	for _, id := range ids {
+		idx.removeRecordLocked(id, idx.tools[id])
+	}
+
+	idx.markSearchDocsDirtyLocked()
+	version := idx.indexVersion
+	listeners := idx.snapshotListenersLocked()
+	idx.mu.Unlock()
+
+	for _, id := range ids {
+		notifyListeners(listeners, ChangeEvent{Type: ChangeToolRemoved, ToolID: id, Version: version})
+	}
+	return len(ids), nil
+}
+
+// TagMatching adds tag to every tool returned by Search(query) and returns
+// how many tools gained the tag. As with TagNamespace, tools already at
+// IndexOptions.MaxTagsPerTool do not gain the tag.
+func (idx *InMemoryIndex) TagMatching(query, tag string) (int, error) {
+	normalized, err := normalizeSingleTag(tag)
+	if err != nil {
+		return 0, err
+	}
+
+	docs, version := idx.snapshotSearchDocs()
+	docs = filterDocs(docs, SearchFilter{})
+	results, _, err := idx.runSearch(idx.activeSearcher(), query, len(docs), docs, version)
+	if err != nil {
+		return 0, err
+	}
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+
+	return idx.applyTag(normalized, func() []string { return ids })
+}
+
+// applyTag adds a normalized tag to the tools returned by selectIDs, which
+// runs under the write lock so selection and tagging are atomic, then
+// notifies listeners once per changed tool.
+func (idx *InMemoryIndex) applyTag(tag string, selectIDs func() []string) (int, error) {
+	idx.mu.Lock()
+	ids := selectIDs()
+	sort.Strings(ids)
+	var changed, truncated []string
+	for _, id := range ids {
+		record, ok := idx.tools[id]
+		if !ok || slices.Contains(record.normalizedTags, tag) {
+			continue
+		}
+		if !idx.tagRoomLocked(record) {
+			truncated = append(truncated, id)
+			continue
+		}
+		record.tool.Tags = append(slices.Clone(record.tool.Tags), tag)
+		record.normalizedTags = append(slices.Clone(record.normalizedTags), tag)
+		record.updatedAt = idx.now()
+		idx.refreshRecordDerived(record)
+		idx.rehashLocked(record)
+		changed = append(changed, id)
+	}
+	if len(changed) == 0 {
+		idx.mu.Unlock()
+		idx.reportTagRejected(truncated, tag)
+		return 0, nil
+	}
+
+	idx.markSearchDocsDirtyLocked()
+	version := idx.indexVersion
+	listeners := idx.snapshotListenersLocked()
+	idx.mu.Unlock()
+
+	idx.reportTagRejected(truncated, tag)
+	for _, id := range changed {
+		notifyListeners(listeners, ChangeEvent{Type: ChangeUpdated, ToolID: id, Version: version})
+	}
+	return len(changed), nil
+}
+
+// reportTagRejected reports tag as dropped for each tool that was already at
+// IndexOptions.MaxTagsPerTool.
+func (idx *InMemoryIndex) reportTagRejected(toolIDs []string, tag string) {
+	for _, id := range toolIDs {
+		idx.reportTruncatedTags(id, []string{tag})
+	}
+}
+
+// normalizeSingleTag normalizes one tag, rejecting tags that normalize away.
+func normalizeSingleTag(tag string) (string, error) {
+	normalized := toolmodel.NormalizeTags([]string{tag})
+	if len(normalized) == 0 {
+		return "", fmt.Errorf("%w: tag %q is empty after normalization", ErrInvalidTool, tag)
+	}
+	return normalized[0], nil
+}
+
+// SetHidden hides or reveals a tool. Hidden tools still resolve via GetTool
+// and GetAllBackends but are excluded from Search, SearchPage, and
+// ListNamespaces unless SearchFilter.IncludeHidden is set.
+func (idx *InMemoryIndex) SetHidden(toolID string, hidden bool) error {
+	idx.mu.Lock()
+	record, exists := idx.tools[toolID]
+	if !exists {
+		idx.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrNotFound, toolID)
+	}
+	if record.hidden == hidden {
+		idx.mu.Unlock()
+		return nil
+	}
+	idx.setHiddenLocked(record, hidden)
+	record.updatedAt = idx.now()
+	idx.rehashLocked(record)
+
+	idx.updateSearchDocsLocked(toolID)
+	version := idx.indexVersion
+	listeners := idx.snapshotListenersLocked()
+	idx.mu.Unlock()
+
+	notifyListeners(listeners, ChangeEvent{Type: ChangeUpdated, ToolID: toolID, Version: version})
+	return nil
+}
+
+// setHiddenLocked updates a record's hidden flag and per-namespace counts.
+// Must be called with idx.mu held.
+func (idx *InMemoryIndex) setHiddenLocked(record *toolRecord, hidden bool) {
+	if record.hidden == hidden {
+		return
+	}
+	record.hidden = hidden
+	ns := record.tool.Namespace
+	if hidden {
+		if idx.hiddenCounts == nil {
+			idx.hiddenCounts = make(map[string]int)
+		}
+		idx.hiddenCounts[ns]++
+		return
+	}
+	if idx.hiddenCounts[ns] <= 1 {
+		delete(idx.hiddenCounts, ns)
+		return
+	}
+	idx.hiddenCounts[ns]--
+}
+
+// MaxPopularity is the largest score SetPopularity accepts. It keeps the
+// popularity boost bounded so it cannot overflow the integer relevance score.
+const MaxPopularity = 1e6
+
+// SetPopularity records a usage-based popularity score for a tool. The
+// default searcher adds IndexOptions.PopularityWeight times this score to the
+// relevance of matching docs. Scores must lie in [0, MaxPopularity]; others,
+// including NaN, are rejected with ErrInvalidTool. The score survives
+// re-registration and does not count as a change for RecentlyChanged.
+func (idx *InMemoryIndex) SetPopularity(toolID string, score float64) error {
+	if math.IsNaN(score) || score < 0 || score > MaxPopularity {
+		return fmt.Errorf("%w: popularity for %q must be between 0 and %g", ErrInvalidTool, toolID, float64(MaxPopularity))
+	}
+
+	idx.mu.Lock()
+	record, exists := idx.tools[toolID]
+	if !exists {
+		idx.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrNotFound, toolID)
+	}
+	if record.popularity == score {
+		idx.mu.Unlock()
+		return nil
+	}
+	record.popularity = score
+	idx.rehashLocked(record)
+
+	idx.updateSearchDocsLocked(toolID)
+	version := idx.indexVersion
+	listeners := idx.snapshotListenersLocked()
+	idx.mu.Unlock()
+
+	notifyListeners(listeners, ChangeEvent{Type: ChangeUpdated, ToolID: toolID, Version: version})
+	return nil
+}
+
+// SetCategory assigns a tool's category, a single controlled-vocabulary
+// classification (for example "communication" or "devops") kept alongside
+// freeform tags. The category is normalized like a tag, indexed for search,
+// and exposed on Summary.Category. An empty category clears it. Categories
+// survive re-registration.
+func (idx *InMemoryIndex) SetCategory(toolID, category string) error {
+	normalized, err := normalizeCategory(toolID, category)
+	if err != nil {
+		return err
+	}
+
+	idx.mu.Lock()
+	record, exists := idx.tools[toolID]
+	if !exists {
+		idx.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrNotFound, toolID)
+	}
+	if record.category == normalized {
+		idx.mu.Unlock()
+		return nil
+	}
+	record.category = normalized
+	record.updatedAt = idx.now()
+	idx.refreshRecordDerived(record)
+	idx.rehashLocked(record)
+
+	idx.updateSearchDocsLocked(toolID)
+	version := idx.indexVersion
+	listeners := idx.snapshotListenersLocked()
+	idx.mu.Unlock()
+
+	notifyListeners(listeners, ChangeEvent{Type: ChangeUpdated, ToolID: toolID, Version: version})
+	return nil
+}
+
+// normalizeCategory normalizes a category with toolmodel.NormalizeTags.
+// A non-empty category that normalizes to nothing is rejected.
+func normalizeCategory(toolID, category string) (string, error) {
+	if category == "" {
+		return "", nil
+	}
+	normalized := toolmodel.NormalizeTags([]string{category})
+	if len(normalized) == 0 {
+		return "", fmt.Errorf("%w: category %q for %q is empty after normalization", ErrInvalidTool, category, toolID)
+	}
+	return normalized[0], nil
+}
+
+// CategoryCount pairs a category with its number of visible tools.
+type CategoryCount struct {
+	Category string
+	Count    int
+}
+
+// ListCategories returns every category assigned to at least one visible
+// tool, with counts, sorted by category. Uncategorized tools are not counted.
+func (idx *InMemoryIndex) ListCategories() []CategoryCount {
+	idx.mu.RLock()
+	counts := make(map[string]int)
+	for _, record := range idx.tools {
+		if record.category != "" && !record.hidden {
+			counts[record.category]++
+		}
+	}
+	idx.mu.RUnlock()
+
+	result := make([]CategoryCount, 0, len(counts))
+	for category, count := range counts {
+		result = append(result, CategoryCount{Category: category, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Category < result[j].Category
+	})
+	return result
+}
@@ -0,0 +1,425 @@
+package toolindex
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/jonwraymond/toolmodel"
+)
+
+func TestDeprecate_SurfacesOnSummary(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("search", "web", "Old web search", nil), makeMCPBackend("s"))
+	mustRegister(t, idx, makeTestTool("search2", "web", "New web search", nil), makeMCPBackend("s"))
+
+	if err := idx.Deprecate("web:search", "web:search2", "use v2"); err != nil {
+		t.Fatalf("Deprecate failed: %v", err)
+	}
+
+	results, err := idx.Search("sear", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[0].ID != "web:search2" {
+		t.Errorf("expected current tool first, got %q", results[0].ID)
+	}
+	old := results[1]
+	if !old.Deprecated || old.ReplacedBy != "web:search2" || old.DeprecationReason != "use v2" {
+		t.Errorf("unexpected deprecation metadata: %+v", old)
+	}
+
+	// Deprecated tools still resolve.
+	if _, _, err := idx.GetTool("web:search"); err != nil {
+		t.Errorf("GetTool on deprecated tool failed: %v", err)
+	}
+
+	// Deprecation survives re-registration.
+	mustRegister(t, idx, makeTestTool("search", "web", "Old web search", []string{"legacy"}), makeMCPBackend("s2"))
+	results, _ = idx.Search("legacy", 10)
+	if len(results) != 1 || !results[0].Deprecated {
+		t.Errorf("expected deprecation to persist, got %+v", results)
+	}
+}
+
+func TestDeprecate_Errors(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("a", "ns", "A", nil), makeMCPBackend("s"))
+
+	if err := idx.Deprecate("ns:missing", "", ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown tool, got %v", err)
+	}
+	if err := idx.Deprecate("ns:a", "ns:missing", ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown replacement, got %v", err)
+	}
+	if err := idx.Deprecate("ns:a", "ns:a", ""); !errors.Is(err, ErrInvalidTool) {
+		t.Errorf("expected ErrInvalidTool for self replacement, got %v", err)
+	}
+}
+
+func TestTagNamespace(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("add", "math", "Add", nil), makeLocalBackend("add"))
+	mustRegister(t, idx, makeTestTool("sub", "math", "Subtract", []string{"Core"}), makeLocalBackend("sub"))
+	mustRegister(t, idx, makeTestTool("fetch", "web", "Fetch", nil), makeLocalBackend("fetch"))
+
+	var events []ChangeEvent
+	idx.OnChange(func(e ChangeEvent) { events = append(events, e) })
+
+	count, err := idx.TagNamespace("math", " Core ")
+	if err != nil {
+		t.Fatalf("TagNamespace failed: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 newly tagged tool, got %d", count)
+	}
+	if len(events) != 1 || events[0].ToolID != "math:add" || events[0].Type != ChangeUpdated {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+
+	results, _ := idx.Search("core", 10)
+	if len(results) != 2 {
+		t.Fatalf("expected both math tools tagged, got %+v", results)
+	}
+
+	if _, err := idx.TagNamespace("math", "!!!"); !errors.Is(err, ErrInvalidTool) {
+		t.Fatalf("expected ErrInvalidTool for empty tag, got %v", err)
+	}
+}
+
+func TestDeleteNamespace(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("add", "math", "Add", nil), makeLocalBackend("add"))
+	mustRegister(t, idx, makeTestTool("add", "math", "Add", nil), makeMCPBackend("calc"))
+	mustRegister(t, idx, makeTestTool("sub", "math", "Subtract", nil), makeLocalBackend("sub"))
+	mustRegister(t, idx, makeTestTool("fetch", "web", "Fetch", nil), makeLocalBackend("fetch"))
+
+	var events []ChangeEvent
+	idx.OnChange(func(e ChangeEvent) { events = append(events, e) })
+
+	removed, err := idx.DeleteNamespace("math")
+	if err != nil {
+		t.Fatalf("DeleteNamespace failed: %v", err)
+	}
+	if removed != 2 {
+		t.Fatalf("expected 2 removed tools, got %d", removed)
+	}
+	if len(events) != 2 || events[0].ToolID != "math:add" || events[1].ToolID != "math:sub" || events[0].Type != ChangeToolRemoved {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+
+	if _, _, err := idx.GetTool("math:add"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+	namespaces, _ := idx.ListNamespaces()
+	if len(namespaces) != 1 || namespaces[0] != "web" {
+		t.Errorf("expected only web namespace, got %v", namespaces)
+	}
+	if results, _ := idx.Search("add", 10); len(results) != 0 {
+		t.Errorf("expected deleted tools out of search, got %+v", results)
+	}
+
+	if _, err := idx.DeleteNamespace("math"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for empty namespace, got %v", err)
+	}
+}
+
+func TestTagMatching(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("fetch", "web", "Fetch a page", nil), makeLocalBackend("fetch"))
+	mustRegister(t, idx, makeTestTool("crawl", "web", "Crawl a page", nil), makeLocalBackend("crawl"))
+	mustRegister(t, idx, makeTestTool("add", "math", "Add numbers", nil), makeLocalBackend("add"))
+
+	count, err := idx.TagMatching("page", "network")
+	if err != nil {
+		t.Fatalf("TagMatching failed: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 tagged tools, got %d", count)
+	}
+
+	tool, _, err := idx.GetTool("web:crawl")
+	if err != nil {
+		t.Fatalf("GetTool failed: %v", err)
+	}
+	if len(tool.Tags) != 1 || tool.Tags[0] != "network" {
+		t.Errorf("expected tool tags to include network, got %v", tool.Tags)
+	}
+
+	// Hidden tools are not discoverable, so a query cannot tag them.
+	mustRegister(t, idx, makeTestTool("scrape", "internal", "Scrape a page", nil), makeLocalBackend("scrape"))
+	if err := idx.SetHidden("internal:scrape", true); err != nil {
+		t.Fatalf("SetHidden failed: %v", err)
+	}
+	if _, err := idx.TagMatching("page", "crawler"); err != nil {
+		t.Fatalf("TagMatching failed: %v", err)
+	}
+	hidden, _, err := idx.GetTool("internal:scrape")
+	if err != nil {
+		t.Fatalf("GetTool failed: %v", err)
+	}
+	if len(hidden.Tags) != 0 {
+		t.Errorf("expected hidden tool left untagged, got %v", hidden.Tags)
+	}
+}
+
+func TestSetHidden_ExcludedFromDiscovery(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("fetch", "web", "Fetch a page", nil), makeLocalBackend("fetch"))
+	mustRegister(t, idx, makeTestTool("plumb", "internal", "Internal plumbing fetch", nil), makeLocalBackend("plumb"))
+
+	if err := idx.SetHidden("internal:plumb", true); err != nil {
+		t.Fatalf("SetHidden failed: %v", err)
+	}
+
+	results, _ := idx.Search("fetch", 10)
+	if len(results) != 1 || results[0].ID != "web:fetch" {
+		t.Fatalf("expected hidden tool excluded, got %+v", results)
+	}
+	page, _, _ := idx.SearchPage("", 10, "")
+	if len(page) != 1 {
+		t.Fatalf("expected hidden tool excluded from SearchPage, got %+v", page)
+	}
+	namespaces, _ := idx.ListNamespaces()
+	if len(namespaces) != 1 || namespaces[0] != "web" {
+		t.Fatalf("expected fully hidden namespace omitted, got %v", namespaces)
+	}
+
+	admin, _ := idx.SearchFiltered("fetch", 10, SearchFilter{IncludeHidden: true})
+	if len(admin) != 2 {
+		t.Fatalf("expected hidden tool with IncludeHidden, got %+v", admin)
+	}
+	if _, _, err := idx.GetTool("internal:plumb"); err != nil {
+		t.Fatalf("hidden tool must still resolve: %v", err)
+	}
+
+	if err := idx.SetHidden("internal:plumb", false); err != nil {
+		t.Fatalf("SetHidden failed: %v", err)
+	}
+	namespaces, _ = idx.ListNamespaces()
+	if len(namespaces) != 2 {
+		t.Fatalf("expected namespace visible again, got %v", namespaces)
+	}
+
+	if err := idx.SetHidden("missing", true); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRegisterTools_HiddenAtRegistration(t *testing.T) {
+	idx := NewInMemoryIndex()
+	err := idx.RegisterTools([]ToolRegistration{
+		{Tool: makeTestTool("plumb", "internal", "Plumbing", nil), Backend: makeLocalBackend("plumb"), Hidden: true},
+	})
+	if err != nil {
+		t.Fatalf("RegisterTools failed: %v", err)
+	}
+	if results, _ := idx.Search("plumb", 10); len(results) != 0 {
+		t.Fatalf("expected hidden tool excluded, got %+v", results)
+	}
+
+	// Removing the last backend clears hidden bookkeeping.
+	if err := idx.UnregisterBackend("internal:plumb", "local", "plumb"); err != nil {
+		t.Fatalf("UnregisterBackend failed: %v", err)
+	}
+	mustRegister(t, idx, makeTestTool("plumb", "internal", "Plumbing", nil), makeLocalBackend("plumb"))
+	if results, _ := idx.Search("plumb", 10); len(results) != 1 {
+		t.Fatalf("expected re-registered tool visible, got %+v", results)
+	}
+	if namespaces, _ := idx.ListNamespaces(); len(namespaces) != 1 {
+		t.Fatalf("expected namespace visible, got %v", namespaces)
+	}
+}
+
+func TestSetPopularity_BoostsRanking(t *testing.T) {
+	idx := NewInMemoryIndex(IndexOptions{PopularityWeight: 0.1})
+	mustRegister(t, idx, makeTestTool("convert_a", "files", "Convert", nil), makeLocalBackend("a"))
+	mustRegister(t, idx, makeTestTool("convert_b", "files", "Convert", nil), makeLocalBackend("b"))
+	mustRegister(t, idx, makeTestTool("other", "misc", "Unrelated", nil), makeLocalBackend("c"))
+
+	results, _ := idx.Search("convert", 10)
+	if len(results) != 2 || results[0].ID != "files:convert_a" {
+		t.Fatalf("expected ID order without popularity, got %+v", results)
+	}
+
+	if err := idx.SetPopularity("files:convert_b", 50); err != nil {
+		t.Fatalf("SetPopularity failed: %v", err)
+	}
+	if err := idx.SetPopularity("misc:other", 1e6); err != nil {
+		t.Fatalf("SetPopularity failed: %v", err)
+	}
+
+	results, _ = idx.Search("convert", 10)
+	if len(results) != 2 || results[0].ID != "files:convert_b" {
+		t.Fatalf("expected popular tool first and non-matching tool excluded, got %+v", results)
+	}
+
+	// Popularity survives re-registration.
+	mustRegister(t, idx, makeTestTool("convert_b", "files", "Convert", []string{"io"}), makeLocalBackend("b"))
+	results, _ = idx.Search("convert", 10)
+	if results[0].ID != "files:convert_b" {
+		t.Fatalf("expected popularity to survive re-registration, got %+v", results)
+	}
+
+	if err := idx.SetPopularity("files:missing", 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if err := idx.SetPopularity("files:convert_a", math.NaN()); !errors.Is(err, ErrInvalidTool) {
+		t.Errorf("expected ErrInvalidTool for NaN, got %v", err)
+	}
+	for _, score := range []float64{-1, MaxPopularity + 1, math.Inf(1)} {
+		if err := idx.SetPopularity("files:convert_a", score); !errors.Is(err, ErrInvalidTool) {
+			t.Errorf("expected ErrInvalidTool for %v, got %v", score, err)
+		}
+	}
+	if err := idx.SetPopularity("files:convert_a", MaxPopularity); err != nil {
+		t.Errorf("expected MaxPopularity to be accepted, got %v", err)
+	}
+}
+
+func TestMergeTool(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("search", "web", "Search the web", []string{"web"}), makeMCPBackend("a"))
+	mustRegister(t, idx, makeTestTool("web", "search", "Web search", []string{"lookup"}), makeMCPBackend("b"))
+	mustRegister(t, idx, makeTestTool("web", "search", "Web search", []string{"lookup"}), makeMCPBackend("a"))
+
+	var events []ChangeEvent
+	idx.OnChange(func(e ChangeEvent) { events = append(events, e) })
+
+	if err := idx.MergeTool("search:web", "web:search", MergeOptions{KeepAlias: true}); err != nil {
+		t.Fatalf("MergeTool failed: %v", err)
+	}
+	if len(events) != 2 || events[0].Type != ChangeToolRemoved || events[0].ToolID != "search:web" ||
+		events[1].Type != ChangeUpdated || events[1].ToolID != "web:search" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+
+	backends, err := idx.GetAllBackends("web:search")
+	if err != nil {
+		t.Fatalf("GetAllBackends failed: %v", err)
+	}
+	if len(backends) != 2 {
+		t.Fatalf("expected backends merged without duplicates, got %+v", backends)
+	}
+	results, _ := idx.Search("lookup", 10)
+	if len(results) != 1 || results[0].ID != "web:search" {
+		t.Fatalf("expected tags unioned onto target, got %+v", results)
+	}
+
+	// The old ID resolves through the alias but no longer appears in discovery.
+	tool, _, err := idx.GetTool("search:web")
+	if err != nil || tool.Name != "search" {
+		t.Fatalf("expected alias to resolve to target, got %+v, %v", tool, err)
+	}
+	if namespaces, _ := idx.ListNamespaces(); len(namespaces) != 1 {
+		t.Fatalf("expected source namespace removed, got %v", namespaces)
+	}
+	if ok, err := idx.HasBackend("search:web", toolmodel.BackendKindMCP, "b"); err != nil || !ok {
+		t.Fatalf("expected HasBackend to resolve the alias, got %v, %v", ok, err)
+	}
+	if page, _, err := idx.GetBackendsPage("search:web", 10, ""); err != nil || len(page) != 2 {
+		t.Fatalf("expected GetBackendsPage to resolve the alias, got %+v, %v", page, err)
+	}
+	_, defaultBackend, _ := idx.GetTool("search:web")
+	ranked, err := idx.GetBackendsRanked("search:web")
+	if err != nil || len(ranked) != 2 || !reflect.DeepEqual(ranked[0], defaultBackend) {
+		t.Fatalf("expected GetBackendsRanked to resolve the alias, got %+v, %v", ranked, err)
+	}
+	if tool, _, _, err := idx.GetToolFuzzy("search:web"); err != nil || tool.Name != "search" {
+		t.Fatalf("expected GetToolFuzzy to resolve the alias, got %+v, %v", tool, err)
+	}
+	if summary, err := idx.GetSummary("search:web"); err != nil || summary.ID != "web:search" {
+		t.Fatalf("expected GetSummary to resolve the alias, got %+v, %v", summary, err)
+	}
+	if _, err := idx.LastSeen("search:web"); err != nil {
+		t.Fatalf("expected LastSeen to resolve the alias, got %v", err)
+	}
+	events = nil
+	if err := idx.UnregisterBackend("search:web", toolmodel.BackendKindMCP, "b"); err != nil {
+		t.Fatalf("expected UnregisterBackend to resolve the alias, got %v", err)
+	}
+	if len(events) != 1 || events[0].ToolID != "web:search" {
+		t.Fatalf("expected event for the target ID, got %+v", events)
+	}
+	if backends, _ := idx.GetAllBackends("web:search"); len(backends) != 1 {
+		t.Fatalf("expected backend removed from target, got %+v", backends)
+	}
+}
+
+func TestMergeTool_Errors(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("a", "ns", "A", nil), makeLocalBackend("a"))
+	other := makeTestTool("b", "ns", "B", nil)
+	other.InputSchema = map[string]any{"type": "object", "properties": map[string]any{"q": map[string]any{"type": "string"}}}
+	mustRegister(t, idx, other, makeLocalBackend("b"))
+
+	if err := idx.MergeTool("ns:a", "ns:b"); !errors.Is(err, ErrInvalidTool) {
+		t.Errorf("expected ErrInvalidTool for schema mismatch, got %v", err)
+	}
+	if err := idx.MergeTool("ns:a", "ns:a"); !errors.Is(err, ErrInvalidTool) {
+		t.Errorf("expected ErrInvalidTool for self-merge, got %v", err)
+	}
+	if err := idx.MergeTool("ns:missing", "ns:a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	// Without KeepAlias, the source ID stops resolving.
+	mustRegister(t, idx, makeTestTool("c", "ns", "C", nil), makeLocalBackend("c"))
+	if err := idx.MergeTool("ns:c", "ns:a"); err != nil {
+		t.Fatalf("MergeTool failed: %v", err)
+	}
+	if _, _, err := idx.GetTool("ns:c"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for merged source, got %v", err)
+	}
+}
+
+func TestSetCategory(t *testing.T) {
+	idx := NewInMemoryIndex()
+	err := idx.RegisterTools([]ToolRegistration{
+		{Tool: makeTestTool("send", "slack", "Send a message", nil), Backend: makeLocalBackend("a"), Category: "Communication"},
+		{Tool: makeTestTool("deploy", "ci", "Deploy a build", nil), Backend: makeLocalBackend("b")},
+		{Tool: makeTestTool("email", "mail", "Send an email", nil), Backend: makeLocalBackend("c")},
+	})
+	if err != nil {
+		t.Fatalf("RegisterTools failed: %v", err)
+	}
+	if err := idx.SetCategory("ci:deploy", "Dev Ops"); err != nil {
+		t.Fatalf("SetCategory failed: %v", err)
+	}
+	if err := idx.SetCategory("mail:email", "communication"); err != nil {
+		t.Fatalf("SetCategory failed: %v", err)
+	}
+
+	results, _ := idx.Search("dev ops", 10)
+	if len(results) != 1 || results[0].ID != "ci:deploy" || results[0].Category != "dev-ops" {
+		t.Fatalf("expected category to be searchable and on Summary, got %+v", results)
+	}
+
+	want := []CategoryCount{{Category: "communication", Count: 2}, {Category: "dev-ops", Count: 1}}
+	if got := idx.ListCategories(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ListCategories = %+v, want %+v", got, want)
+	}
+
+	// Re-registration without a category keeps it; an empty category clears it.
+	mustRegister(t, idx, makeTestTool("deploy", "ci", "Deploy a build", nil), makeLocalBackend("b"))
+	if results, _ := idx.Search("dev ops", 10); len(results) != 1 {
+		t.Fatalf("expected category to survive re-registration, got %+v", results)
+	}
+	if err := idx.SetCategory("ci:deploy", ""); err != nil {
+		t.Fatalf("SetCategory clear failed: %v", err)
+	}
+	if got := idx.ListCategories(); len(got) != 1 {
+		t.Fatalf("expected cleared category to drop out, got %+v", got)
+	}
+
+	if err := idx.SetCategory("ci:deploy", "!!!"); !errors.Is(err, ErrInvalidTool) {
+		t.Errorf("expected ErrInvalidTool for invalid category, got %v", err)
+	}
+	if err := idx.SetCategory("ci:missing", "data"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package toolindex
+
+import (
+	"sync"
+	"time"
+)
+
+// DebounceListener wraps listener so that bursts of change events are
+// coalesced: events are buffered for window after the first one arrives, and
+// then only the latest event per tool ID is delivered, in order of each
+// tool's first event in the window. Index-wide events such as
+// ChangeRefreshed share the empty tool ID and coalesce with each other.
+//
+// The wrapped listener returns immediately, so a slow downstream no longer
+// stalls registration; deliveries run on a timer goroutine, one at a time.
+// Because intermediate events are dropped, consumers should treat a
+// delivered event as "this tool changed" and re-read its current state.
+//
+// The returned stop function delivers any pending events, waits for a
+// delivery already in progress, and makes later events pass straight
+// through. It is safe to call multiple times, but not from within listener.
+// A non-positive window returns listener unchanged.
+func DebounceListener(listener ChangeListener, window time.Duration) (ChangeListener, func()) {
+	if window <= 0 || listener == nil {
+		return listener, func() {}
+	}
+	d := &debouncer{listener: listener, window: window, pending: make(map[string]ChangeEvent)}
+	return d.receive, d.stop
+}
+
+type debouncer struct {
+	listener ChangeListener
+	window   time.Duration
+
+	mu      sync.Mutex
+	pending map[string]ChangeEvent // latest event per tool ID
+	order   []string               // tool IDs by first event in the window
+	timer   *time.Timer
+	stopped bool
+	flushes sync.WaitGroup // timer flushes scheduled or running
+
+	deliverMu sync.Mutex // serializes calls to listener
+}
+
+// receive buffers an event, starting the window timer if none is running.
+func (d *debouncer) receive(event ChangeEvent) {
+	d.mu.Lock()
+	if d.stopped {
+		d.mu.Unlock()
+		d.deliver([]ChangeEvent{event})
+		return
+	}
+	if _, ok := d.pending[event.ToolID]; !ok {
+		d.order = append(d.order, event.ToolID)
+	}
+	d.pending[event.ToolID] = event
+	if d.timer == nil {
+		d.flushes.Add(1)
+		d.timer = time.AfterFunc(d.window, func() {
+			defer d.flushes.Done()
+			d.flush()
+		})
+	}
+	d.mu.Unlock()
+}
+
+// flush delivers the pending events and resets the window.
+func (d *debouncer) flush() {
+	d.mu.Lock()
+	events := make([]ChangeEvent, len(d.order))
+	for i, id := range d.order {
+		events[i] = d.pending[id]
+	}
+	clear(d.pending)
+	d.order = d.order[:0]
+	d.timer = nil
+	d.mu.Unlock()
+
+	d.deliver(events)
+}
+
+func (d *debouncer) stop() {
+	d.mu.Lock()
+	if d.stopped {
+		d.mu.Unlock()
+		return
+	}
+	d.stopped = true
+	if d.timer != nil && d.timer.Stop() {
+		d.flushes.Done() // the timer will never run
+	}
+	d.mu.Unlock()
+
+	d.flush()
+	d.flushes.Wait()
+}
+
+func (d *debouncer) deliver(events []ChangeEvent) {
+	if len(events) == 0 {
+		return
+	}
+	d.deliverMu.Lock()
+	defer d.deliverMu.Unlock()
+	for _, event := range events {
+		d.listener(event)
+	}
+}
@@ -0,0 +1,113 @@
+package toolindex
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDebounceListener_CoalescesPerTool(t *testing.T) {
+	var mu sync.Mutex
+	var got []ChangeEvent
+	listener, stop := DebounceListener(func(e ChangeEvent) {
+		mu.Lock()
+		got = append(got, e)
+		mu.Unlock()
+	}, time.Hour)
+
+	idx := NewInMemoryIndex()
+	unsubscribe := idx.OnChange(listener)
+	defer unsubscribe()
+
+	for i := 0; i < 5; i++ {
+		mustRegister(t, idx, makeTestTool("b", "ns", "B", nil), makeLocalBackend("b"))
+	}
+	mustRegister(t, idx, makeTestTool("a", "ns", "A", nil), makeLocalBackend("a"))
+	if err := idx.UnregisterBackend("ns:b", makeLocalBackend("b").Kind, "b"); err != nil {
+		t.Fatalf("UnregisterBackend failed: %v", err)
+	}
+
+	mu.Lock()
+	if len(got) != 0 {
+		t.Fatalf("expected no delivery within the window, got %+v", got)
+	}
+	mu.Unlock()
+
+	stop()
+	if len(got) != 2 {
+		t.Fatalf("expected one event per tool, got %+v", got)
+	}
+	if got[0].ToolID != "ns:b" || got[0].Type != ChangeToolRemoved {
+		t.Errorf("expected latest event for ns:b first, got %+v", got[0])
+	}
+	if got[1].ToolID != "ns:a" || got[1].Type != ChangeRegistered {
+		t.Errorf("expected ns:a registration second, got %+v", got[1])
+	}
+
+	// After stop, events pass straight through.
+	mustRegister(t, idx, makeTestTool("c", "ns", "C", nil), makeLocalBackend("c"))
+	if len(got) != 3 || got[2].ToolID != "ns:c" {
+		t.Fatalf("expected pass-through after stop, got %+v", got)
+	}
+	stop()
+}
+
+func TestDebounceListener_DeliversAfterWindow(t *testing.T) {
+	delivered := make(chan ChangeEvent, 10)
+	listener, stop := DebounceListener(func(e ChangeEvent) { delivered <- e }, 10*time.Millisecond)
+	defer stop()
+
+	for v := uint64(1); v <= 3; v++ {
+		listener(ChangeEvent{Type: ChangeUpdated, ToolID: "ns:x", Version: v})
+	}
+	select {
+	case e := <-delivered:
+		if e.Version != 3 {
+			t.Fatalf("expected the latest event, got %+v", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for debounced delivery")
+	}
+	select {
+	case e := <-delivered:
+		t.Fatalf("expected a single delivery, got extra %+v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if same, _ := DebounceListener(nil, time.Second); same != nil {
+		t.Error("expected nil listener to be returned unchanged")
+	}
+}
+
+func TestDebounceListener_StopWaitsForInFlightDelivery(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	listener, stop := DebounceListener(func(ChangeEvent) {
+		close(entered)
+		<-release
+	}, time.Millisecond)
+
+	listener(ChangeEvent{Type: ChangeUpdated, ToolID: "ns:x"})
+	select {
+	case <-entered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for debounced delivery")
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("stop returned while a delivery was still running")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stop did not return after the delivery finished")
+	}
+}
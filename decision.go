@@ -0,0 +1,45 @@
+package toolindex
+
+import "github.com/jonwraymond/toolmodel"
+
+// DecisionType identifies a branch taken while registering a tool.
+// Namespaces are part of the tool ID, so re-registration never moves a tool
+// between namespaces and there is no namespace-change decision.
+type DecisionType string
+
+const (
+	// DecisionToolAdded: the tool ID was new and a record was created.
+	DecisionToolAdded DecisionType = "tool_added"
+	// DecisionFieldsMismatch: re-registration was rejected because the MCP
+	// fields differ from the existing registration.
+	DecisionFieldsMismatch DecisionType = "fields_mismatch"
+	// DecisionBackendConflict: re-registration was rejected by
+	// StrictBackendReplace.
+	DecisionBackendConflict DecisionType = "backend_conflict"
+	// DecisionBackendAdded: a backend with a new identity was appended.
+	DecisionBackendAdded DecisionType = "backend_added"
+	// DecisionBackendReplaced: a backend with an existing identity replaced
+	// the stored one.
+	DecisionBackendReplaced DecisionType = "backend_replaced"
+	// DecisionNoOp: the registration matched the stored tool, tags, and
+	// backend exactly. It is reported instead of DecisionBackendReplaced; the
+	// registration still refreshes the tool's last-seen time and notifies
+	// listeners.
+	DecisionNoOp DecisionType = "no_op"
+)
+
+// DecisionEvent describes one registration decision; see IndexOptions.OnDecision.
+type DecisionEvent struct {
+	Type    DecisionType
+	ToolID  string
+	Backend toolmodel.ToolBackend
+	Detail  string
+}
+
+// emitDecisions delivers decisions to the OnDecision hook.
+// Must be called without idx.mu held.
+func (idx *InMemoryIndex) emitDecisions(decisions []DecisionEvent) {
+	for _, d := range decisions {
+		idx.onDecision(d)
+	}
+}
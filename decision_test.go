@@ -0,0 +1,70 @@
+package toolindex
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestOnDecision(t *testing.T) {
+	var got []DecisionType
+	idx := NewInMemoryIndex(IndexOptions{
+		OnDecision: func(e DecisionEvent) {
+			if e.ToolID != "ns:tool" {
+				t.Errorf("unexpected tool ID %q", e.ToolID)
+			}
+			got = append(got, e.Type)
+		},
+	})
+	tool := makeTestTool("tool", "ns", "A tool", nil)
+
+	mustRegister(t, idx, tool, makeMCPBackend("a"))
+	mustRegister(t, idx, tool, makeMCPBackend("b"))
+	mustRegister(t, idx, tool, makeMCPBackend("b"))
+	changed := makeTestTool("tool", "ns", "Changed", nil)
+	if err := idx.RegisterTool(changed, makeMCPBackend("a")); !errors.Is(err, ErrInvalidTool) {
+		t.Fatalf("expected ErrInvalidTool, got %v", err)
+	}
+
+	want := []DecisionType{
+		DecisionToolAdded,
+		DecisionBackendAdded,
+		DecisionNoOp,
+		DecisionFieldsMismatch,
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected decisions %v, got %v", want, got)
+	}
+}
+
+func TestOnDecision_IdenticalReRegistration(t *testing.T) {
+	var got []DecisionType
+	idx := NewInMemoryIndex(IndexOptions{
+		OnDecision: func(e DecisionEvent) { got = append(got, e.Type) },
+	})
+	tool := makeTestTool("tool", "ns", "A tool", []string{"alpha"})
+
+	mustRegister(t, idx, tool, makeMCPBackend("a"))
+	mustRegister(t, idx, tool, makeMCPBackend("a"))
+	if want := []DecisionType{DecisionToolAdded, DecisionNoOp}; !slices.Equal(got, want) {
+		t.Fatalf("expected decisions %v, got %v", want, got)
+	}
+
+	// A changed tag set is a replacement, not a no-op.
+	got = nil
+	mustRegister(t, idx, makeTestTool("tool", "ns", "A tool", []string{"beta"}), makeMCPBackend("a"))
+	if want := []DecisionType{DecisionBackendReplaced}; !slices.Equal(got, want) {
+		t.Fatalf("expected decisions %v, got %v", want, got)
+	}
+}
+
+func TestOnDecision_CalledOutsideLock(t *testing.T) {
+	var idx *InMemoryIndex
+	idx = NewInMemoryIndex(IndexOptions{
+		OnDecision: func(DecisionEvent) {
+			// Would deadlock if called with idx.mu held.
+			_, _ = idx.ListNamespaces()
+		},
+	})
+	mustRegister(t, idx, makeTestTool("tool", "ns", "A tool", nil), makeMCPBackend("a"))
+}
@@ -0,0 +1,226 @@
+package toolindex
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/jonwraymond/toolmodel"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SummaryDTO is a plain, wire-stable representation of Summary intended as a
+// target for protobuf/gRPC code generation.
+type SummaryDTO struct {
+	ID                string   `json:"id"`
+	Name              string   `json:"name"`
+	Namespace         string   `json:"namespace"`
+	ShortDescription  string   `json:"short_description"`
+	Preview           string   `json:"preview"`
+	Tags              []string `json:"tags"`
+	Deprecated        bool     `json:"deprecated"`
+	ReplacedBy        string   `json:"replaced_by"`
+	DeprecationReason string   `json:"deprecation_reason"`
+	Category          string   `json:"category,omitempty"`
+}
+
+// BackendDTO is a flattened, wire-stable representation of toolmodel.ToolBackend.
+// Only the fields relevant to Kind are populated.
+type BackendDTO struct {
+	Kind       string `json:"kind"`
+	ServerName string `json:"server_name,omitempty"`
+	ProviderID string `json:"provider_id,omitempty"`
+	ToolID     string `json:"tool_id,omitempty"`
+	LocalName  string `json:"local_name,omitempty"`
+}
+
+// ToolDTO is a wire-stable representation of a tool and its backends.
+// Schema-like fields are carried as JSON strings since proto messages cannot
+// hold arbitrary values.
+type ToolDTO struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	Namespace        string       `json:"namespace"`
+	Version          string       `json:"version"`
+	Title            string       `json:"title"`
+	Description      string       `json:"description"`
+	Tags             []string     `json:"tags"`
+	InputSchemaJSON  string       `json:"input_schema_json"`
+	OutputSchemaJSON string       `json:"output_schema_json"`
+	AnnotationsJSON  string       `json:"annotations_json"`
+	IconsJSON        string       `json:"icons_json"`
+	MetaJSON         string       `json:"meta_json"`
+	Backends         []BackendDTO `json:"backends"`
+}
+
+// ToDTO converts a Summary to its wire representation.
+func (s Summary) ToDTO() SummaryDTO {
+	return SummaryDTO{
+		ID:                s.ID,
+		Name:              s.Name,
+		Namespace:         s.Namespace,
+		ShortDescription:  s.ShortDescription,
+		Preview:           s.Preview,
+		Tags:              slices.Clone(s.Tags),
+		Deprecated:        s.Deprecated,
+		ReplacedBy:        s.ReplacedBy,
+		DeprecationReason: s.DeprecationReason,
+		Category:          s.Category,
+	}
+}
+
+// SummaryFromDTO converts a SummaryDTO back to a Summary.
+func SummaryFromDTO(dto SummaryDTO) Summary {
+	return Summary{
+		ID:                dto.ID,
+		Name:              dto.Name,
+		Namespace:         dto.Namespace,
+		ShortDescription:  dto.ShortDescription,
+		Preview:           dto.Preview,
+		Tags:              slices.Clone(dto.Tags),
+		Deprecated:        dto.Deprecated,
+		ReplacedBy:        dto.ReplacedBy,
+		DeprecationReason: dto.DeprecationReason,
+		Category:          dto.Category,
+	}
+}
+
+// BackendToDTO converts a backend to its wire representation.
+func BackendToDTO(backend toolmodel.ToolBackend) BackendDTO {
+	dto := BackendDTO{Kind: string(backend.Kind)}
+	switch {
+	case backend.MCP != nil:
+		dto.ServerName = backend.MCP.ServerName
+	case backend.Provider != nil:
+		dto.ProviderID = backend.Provider.ProviderID
+		dto.ToolID = backend.Provider.ToolID
+	case backend.Local != nil:
+		dto.LocalName = backend.Local.Name
+	}
+	return dto
+}
+
+// BackendFromDTO converts a BackendDTO back to a backend.
+func BackendFromDTO(dto BackendDTO) (toolmodel.ToolBackend, error) {
+	backend := toolmodel.ToolBackend{Kind: toolmodel.BackendKind(dto.Kind)}
+	switch backend.Kind {
+	case toolmodel.BackendKindMCP:
+		backend.MCP = &toolmodel.MCPBackend{ServerName: dto.ServerName}
+	case toolmodel.BackendKindProvider:
+		backend.Provider = &toolmodel.ProviderBackend{ProviderID: dto.ProviderID, ToolID: dto.ToolID}
+	case toolmodel.BackendKindLocal:
+		backend.Local = &toolmodel.LocalBackend{Name: dto.LocalName}
+	default:
+		return toolmodel.ToolBackend{}, fmt.Errorf("%w: unknown backend kind %q", ErrInvalidBackend, dto.Kind)
+	}
+	return backend, nil
+}
+
+// ToolToDTO converts a tool and its backends to the wire representation.
+func ToolToDTO(tool toolmodel.Tool, backends []toolmodel.ToolBackend) (ToolDTO, error) {
+	dto := ToolDTO{
+		ID:          formatToolID(tool.Namespace, tool.Name),
+		Name:        tool.Name,
+		Namespace:   tool.Namespace,
+		Version:     tool.Version,
+		Title:       tool.Title,
+		Description: tool.Description,
+		Tags:        slices.Clone(tool.Tags),
+		Backends:    make([]BackendDTO, len(backends)),
+	}
+	fields := []struct {
+		dst *string
+		src any
+	}{
+		{&dto.InputSchemaJSON, tool.InputSchema},
+		{&dto.OutputSchemaJSON, tool.OutputSchema},
+		{&dto.AnnotationsJSON, tool.Annotations},
+		{&dto.IconsJSON, tool.Icons},
+		{&dto.MetaJSON, tool.Meta},
+	}
+	for _, f := range fields {
+		encoded, err := encodeDTOJSON(f.src)
+		if err != nil {
+			return ToolDTO{}, fmt.Errorf("%w: %v", ErrInvalidTool, err)
+		}
+		*f.dst = encoded
+	}
+	for i, b := range backends {
+		dto.Backends[i] = BackendToDTO(b)
+	}
+	return dto, nil
+}
+
+// ToolFromDTO converts a ToolDTO back to a tool and its backends.
+func ToolFromDTO(dto ToolDTO) (toolmodel.Tool, []toolmodel.ToolBackend, error) {
+	tool := toolmodel.Tool{
+		Tool: mcp.Tool{
+			Name:        dto.Name,
+			Title:       dto.Title,
+			Description: dto.Description,
+		},
+		Namespace: dto.Namespace,
+		Version:   dto.Version,
+		Tags:      slices.Clone(dto.Tags),
+	}
+	var inputSchema, outputSchema any
+	fields := []struct {
+		src string
+		dst any
+	}{
+		{dto.InputSchemaJSON, &inputSchema},
+		{dto.OutputSchemaJSON, &outputSchema},
+		{dto.AnnotationsJSON, &tool.Annotations},
+		{dto.IconsJSON, &tool.Icons},
+		{dto.MetaJSON, &tool.Meta},
+	}
+	for _, f := range fields {
+		if f.src == "" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(f.src), f.dst); err != nil {
+			return toolmodel.Tool{}, nil, fmt.Errorf("%w: %v", ErrInvalidTool, err)
+		}
+	}
+	tool.InputSchema = inputSchema
+	tool.OutputSchema = outputSchema
+
+	backends := make([]toolmodel.ToolBackend, len(dto.Backends))
+	for i, b := range dto.Backends {
+		backend, err := BackendFromDTO(b)
+		if err != nil {
+			return toolmodel.Tool{}, nil, err
+		}
+		backends[i] = backend
+	}
+	return tool, backends, nil
+}
+
+// encodeDTOJSON encodes v as a JSON string, using "" for absent values.
+func encodeDTOJSON(v any) (string, error) {
+	switch val := v.(type) {
+	case nil:
+		return "", nil
+	case *mcp.ToolAnnotations:
+		if val == nil {
+			return "", nil
+		}
+	case []mcp.Icon:
+		if len(val) == 0 {
+			return "", nil
+		}
+	case mcp.Meta:
+		if len(val) == 0 {
+			return "", nil
+		}
+	case json.RawMessage:
+		return string(val), nil
+	case []byte:
+		return string(val), nil
+	}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
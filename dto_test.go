@@ -0,0 +1,59 @@
+package toolindex
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jonwraymond/toolmodel"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestSummaryDTO_RoundTrip(t *testing.T) {
+	s := Summary{
+		ID:         "ns:tool",
+		Name:       "tool",
+		Namespace:  "ns",
+		Tags:       []string{"a", "b"},
+		Deprecated: true,
+		ReplacedBy: "ns:tool2",
+		Category:   "math",
+	}
+	if got := SummaryFromDTO(s.ToDTO()); !reflect.DeepEqual(got, s) {
+		t.Fatalf("round trip mismatch: %+v", got)
+	}
+}
+
+func TestToolDTO_RoundTrip(t *testing.T) {
+	tool := makeTestTool("calc", "math", "Calculator", []string{"math"})
+	tool.Title = "Calc"
+	tool.OutputSchema = map[string]any{"type": "number"}
+	tool.Annotations = &mcp.ToolAnnotations{ReadOnlyHint: true}
+	tool.Icons = []mcp.Icon{{Source: "https://example.com/calc.png"}}
+	backends := []toolmodel.ToolBackend{
+		makeMCPBackend("server"),
+		makeProviderBackend("p", "calc"),
+		makeLocalBackend("calc"),
+	}
+
+	dto, err := ToolToDTO(tool, backends)
+	if err != nil {
+		t.Fatalf("ToolToDTO failed: %v", err)
+	}
+	if dto.ID != "math:calc" || dto.InputSchemaJSON == "" || len(dto.Backends) != 3 {
+		t.Fatalf("unexpected dto: %+v", dto)
+	}
+
+	gotTool, gotBackends, err := ToolFromDTO(dto)
+	if err != nil {
+		t.Fatalf("ToolFromDTO failed: %v", err)
+	}
+	if !toolMCPFieldsEqual(gotTool, tool) {
+		t.Fatalf("MCP fields differ after round trip: %+v", gotTool)
+	}
+	if gotTool.Namespace != "math" || !reflect.DeepEqual(gotTool.Tags, tool.Tags) {
+		t.Fatalf("extension fields differ after round trip: %+v", gotTool)
+	}
+	if !reflect.DeepEqual(gotBackends, backends) {
+		t.Fatalf("backends differ after round trip: %+v", gotBackends)
+	}
+}
@@ -0,0 +1,94 @@
+package toolindex
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+
+	"github.com/jonwraymond/toolmodel"
+)
+
+// Fingerprint returns a content hash of the catalog. Unlike the index
+// version, it depends only on what is stored, not on mutation history: two
+// indexes (or one index before and after a rollback) holding the same tools,
+// backends, and curation state have the same fingerprint. Timestamps are
+// not part of the content.
+//
+// With IndexOptions.TrackFingerprint the fingerprint is maintained
+// incrementally on each mutation; otherwise it is computed on demand.
+func (idx *InMemoryIndex) Fingerprint() string {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if idx.trackFingerprint {
+		return formatFingerprint(idx.fingerprint)
+	}
+	var sum uint64
+	for id, record := range idx.tools {
+		sum ^= recordHash(id, record)
+	}
+	return formatFingerprint(sum)
+}
+
+// rehashLocked refreshes a record's contribution to the tracked fingerprint
+// after it changed. Must be called with idx.mu held.
+func (idx *InMemoryIndex) rehashLocked(record *toolRecord) {
+	if !idx.trackFingerprint {
+		return
+	}
+	idx.fingerprint ^= record.contentHash
+	record.contentHash = recordHash(record.summary.ID, record)
+	idx.fingerprint ^= record.contentHash
+}
+
+// unhashLocked removes a record's contribution to the tracked fingerprint.
+// Must be called with idx.mu held.
+func (idx *InMemoryIndex) unhashLocked(record *toolRecord) {
+	idx.fingerprint ^= record.contentHash
+	record.contentHash = 0
+}
+
+// recordHash hashes the content of one tool record. Per-tool hashes are
+// combined with XOR, so the catalog fingerprint is independent of order.
+func recordHash(id string, record *toolRecord) uint64 {
+	backends := slices.Clone(record.backends)
+	slices.SortFunc(backends, func(a, b toolmodel.ToolBackend) int {
+		return strings.Compare(backendIdentity(a), backendIdentity(b))
+	})
+	content := struct {
+		ID         string                  `json:"id"`
+		Tool       toolmodel.Tool          `json:"tool"`
+		Backends   []toolmodel.ToolBackend `json:"backends"`
+		ReplacedBy string                  `json:"replacedBy,omitempty"`
+		Reason     string                  `json:"reason,omitempty"`
+		Deprecated bool                    `json:"deprecated,omitempty"`
+		Hidden     bool                    `json:"hidden,omitempty"`
+		Popularity float64                 `json:"popularity,omitempty"`
+		Category   string                  `json:"category,omitempty"`
+	}{
+		ID:         id,
+		Tool:       record.tool,
+		Backends:   backends,
+		Hidden:     record.hidden,
+		Popularity: record.popularity,
+		Category:   record.category,
+	}
+	if record.deprecation != nil {
+		content.Deprecated = true
+		content.ReplacedBy = record.deprecation.replacedBy
+		content.Reason = record.deprecation.reason
+	}
+
+	h := fnv.New64a()
+	if err := json.NewEncoder(h).Encode(content); err != nil {
+		// Unencodable schemas still contribute their identity.
+		_, _ = h.Write([]byte(id))
+	}
+	return h.Sum64()
+}
+
+func formatFingerprint(sum uint64) string {
+	return fmt.Sprintf("%016x", sum)
+}
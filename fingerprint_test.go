@@ -0,0 +1,50 @@
+package toolindex
+
+import (
+	"testing"
+
+	"github.com/jonwraymond/toolmodel"
+)
+
+func TestFingerprint_ContentAddressed(t *testing.T) {
+	for _, track := range []bool{false, true} {
+		a := NewInMemoryIndex(IndexOptions{TrackFingerprint: track})
+		b := NewInMemoryIndex(IndexOptions{TrackFingerprint: track})
+
+		// Same content, registered in a different order.
+		mustRegister(t, a, makeTestTool("x", "ns", "X", nil), makeLocalBackend("x"))
+		mustRegister(t, a, makeTestTool("y", "ns", "Y", nil), makeLocalBackend("y"))
+		mustRegister(t, b, makeTestTool("y", "ns", "Y", nil), makeLocalBackend("y"))
+		mustRegister(t, b, makeTestTool("x", "ns", "X", nil), makeLocalBackend("x"))
+		if a.Fingerprint() != b.Fingerprint() {
+			t.Fatalf("track=%v: expected equal fingerprints for equal content", track)
+		}
+
+		// A change and its rollback.
+		before := a.Fingerprint()
+		mustRegister(t, a, makeTestTool("x", "ns", "X", nil), makeMCPBackend("extra"))
+		if err := a.SetPopularity("ns:y", 3); err != nil {
+			t.Fatalf("SetPopularity failed: %v", err)
+		}
+		if a.Fingerprint() == before {
+			t.Fatalf("track=%v: expected fingerprint to change", track)
+		}
+		if err := a.UnregisterBackend("ns:x", toolmodel.BackendKindMCP, "extra"); err != nil {
+			t.Fatalf("UnregisterBackend failed: %v", err)
+		}
+		if err := a.SetPopularity("ns:y", 0); err != nil {
+			t.Fatalf("SetPopularity failed: %v", err)
+		}
+		if a.Fingerprint() != before {
+			t.Fatalf("track=%v: expected rollback to restore the fingerprint", track)
+		}
+
+		// Removing everything yields the empty fingerprint.
+		if _, err := a.DeleteNamespace("ns"); err != nil {
+			t.Fatalf("DeleteNamespace failed: %v", err)
+		}
+		if got, want := a.Fingerprint(), NewInMemoryIndex().Fingerprint(); got != want {
+			t.Fatalf("track=%v: expected empty fingerprint %s, got %s", track, want, got)
+		}
+	}
+}
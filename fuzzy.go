@@ -0,0 +1,208 @@
+package toolindex
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jonwraymond/toolmodel"
+)
+
+const (
+	// maxFuzzyIDDistance bounds the edit distance for GetToolFuzzy candidates.
+	maxFuzzyIDDistance = 3
+	// maxFuzzyIDCandidates bounds the number of candidates GetToolFuzzy returns.
+	maxFuzzyIDCandidates = 5
+)
+
+// GetToolFuzzy behaves like GetTool for exact matches. On a miss it returns
+// ErrNotFound together with up to five registered IDs within a small edit
+// distance of id, closest first, so callers can suggest corrections. Hidden
+// tools resolve by exact ID but are never suggested.
+func (idx *InMemoryIndex) GetToolFuzzy(id string) (toolmodel.Tool, toolmodel.ToolBackend, []string, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if record, exists := idx.lookupLocked(id); exists {
+		backend, ok := SelectBackend(idx.backendSelector, record.backends)
+		if !ok {
+			return toolmodel.Tool{}, toolmodel.ToolBackend{}, nil, fmt.Errorf("%w: %s", ErrNoBackend, id)
+		}
+		return record.tool, backend, nil, nil
+	}
+
+	type candidate struct {
+		id       string
+		distance int
+	}
+	var candidates []candidate
+	for candidateID, record := range idx.tools {
+		if record.hidden {
+			continue
+		}
+		if d := boundedLevenshtein(id, candidateID, maxFuzzyIDDistance); d >= 0 {
+			candidates = append(candidates, candidate{id: candidateID, distance: d})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance == candidates[j].distance {
+			return candidates[i].id < candidates[j].id
+		}
+		return candidates[i].distance < candidates[j].distance
+	})
+	if len(candidates) > maxFuzzyIDCandidates {
+		candidates = candidates[:maxFuzzyIDCandidates]
+	}
+
+	ids := make([]string, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.id
+	}
+	return toolmodel.Tool{}, toolmodel.ToolBackend{}, ids, fmt.Errorf("%w: %s", ErrNotFound, id)
+}
+
+// boundedLevenshtein returns the edit distance between a and b, or -1 if it
+// exceeds maxDistance. Rows are abandoned early once every cell exceeds the
+// bound, keeping the cost proportional to maxDistance for distant strings.
+func boundedLevenshtein(a, b string, maxDistance int) int {
+	ra, rb := []rune(a), []rune(b)
+	if diff := len(ra) - len(rb); diff > maxDistance || -diff > maxDistance {
+		return -1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > maxDistance {
+			return -1
+		}
+		prev, curr = curr, prev
+	}
+	if prev[len(rb)] > maxDistance {
+		return -1
+	}
+	return prev[len(rb)]
+}
+
+// NewFuzzySearcher returns a typo-tolerant Searcher that matches the query
+// against each tool's name and tags by edit distance, so "calcualtor" still
+// finds "calculator". Comparison ignores case and treats underscores and
+// hyphens as spaces, like the default searcher.
+//
+// Tools within maxDistance edits of the query are returned, closest first;
+// at equal distance a name match outranks a tag match, then IDs ascend. A
+// negative maxDistance is treated as zero. An empty query returns docs in
+// input order up to limit. The searcher implements Explainer, scoring each
+// result maxDistance+1 minus its distance, and DeterministicSearcher.
+func NewFuzzySearcher(maxDistance int) Searcher {
+	return &fuzzySearcher{maxDistance: max(maxDistance, 0)}
+}
+
+type fuzzySearcher struct {
+	maxDistance int
+}
+
+// fuzzyMatch is one doc's closest match and the field it came from.
+type fuzzyMatch struct {
+	doc      SearchDoc
+	distance int
+	viaTag   bool
+}
+
+// Search implements Searcher.
+func (f *fuzzySearcher) Search(query string, limit int, docs []SearchDoc) ([]Summary, error) {
+	matches := f.rank(query, limit, docs)
+	results := make([]Summary, len(matches))
+	for i, m := range matches {
+		results[i] = m.doc.Summary
+	}
+	return results, nil
+}
+
+// Explain implements Explainer.
+func (f *fuzzySearcher) Explain(query string, limit int, docs []SearchDoc) ([]Explanation, error) {
+	matches := f.rank(query, limit, docs)
+	results := make([]Explanation, len(matches))
+	for i, m := range matches {
+		field := "name"
+		if m.viaTag {
+			field = "tag"
+		}
+		points := f.maxDistance + 1 - m.distance
+		results[i] = Explanation{
+			Summary:    m.doc.Summary,
+			Score:      points,
+			Components: []ScoreComponent{{Reason: fmt.Sprintf("%s within %d edits", field, m.distance), Points: points}},
+		}
+	}
+	return results, nil
+}
+
+// Deterministic implements DeterministicSearcher.
+func (f *fuzzySearcher) Deterministic() bool {
+	return true
+}
+
+func (f *fuzzySearcher) rank(query string, limit int, docs []SearchDoc) []fuzzyMatch {
+	if limit <= 0 {
+		return nil
+	}
+	query = strings.ToLower(strings.TrimSpace(normalizeSeparators(query)))
+	if query == "" {
+		matches := make([]fuzzyMatch, 0, min(limit, len(docs)))
+		for _, doc := range docs[:min(limit, len(docs))] {
+			matches = append(matches, fuzzyMatch{doc: doc})
+		}
+		return matches
+	}
+
+	var matches []fuzzyMatch
+	for _, doc := range docs {
+		if m, ok := f.match(query, doc); ok {
+			matches = append(matches, m)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.distance != b.distance {
+			return a.distance < b.distance
+		}
+		if a.viaTag != b.viaTag {
+			return !a.viaTag
+		}
+		return a.doc.Summary.ID < b.doc.Summary.ID
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// match returns the closest of doc's name and tags to query, preferring the
+// name on ties.
+func (f *fuzzySearcher) match(query string, doc SearchDoc) (fuzzyMatch, bool) {
+	best := fuzzyMatch{doc: doc, distance: -1}
+	if d := boundedLevenshtein(query, strings.ToLower(normalizeSeparators(doc.Summary.Name)), f.maxDistance); d >= 0 {
+		best.distance = d
+	}
+	for _, tag := range doc.Summary.Tags {
+		d := boundedLevenshtein(query, strings.ToLower(normalizeSeparators(tag)), f.maxDistance)
+		if d >= 0 && (best.distance < 0 || d < best.distance) {
+			best.distance, best.viaTag = d, true
+		}
+	}
+	return best, best.distance >= 0
+}
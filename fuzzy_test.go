@@ -0,0 +1,104 @@
+package toolindex
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGetToolFuzzy_SuggestsCloseIDs(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("calc", "maths", "Calculator", nil), makeLocalBackend("calc"))
+	mustRegister(t, idx, makeTestTool("calc", "stats", "Stats calculator", nil), makeLocalBackend("stats"))
+	mustRegister(t, idx, makeTestTool("fetch", "web", "Fetch", nil), makeLocalBackend("fetch"))
+
+	_, _, candidates, err := idx.GetToolFuzzy("math:calc")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if !reflect.DeepEqual(candidates, []string{"maths:calc", "stats:calc"}) {
+		t.Fatalf("unexpected candidates: %v", candidates)
+	}
+
+	tool, _, candidates, err := idx.GetToolFuzzy("web:fetch")
+	if err != nil {
+		t.Fatalf("exact lookup failed: %v", err)
+	}
+	if tool.Name != "fetch" || candidates != nil {
+		t.Fatalf("unexpected exact result: %q, %v", tool.Name, candidates)
+	}
+}
+
+func TestGetToolFuzzy_NeverSuggestsHiddenTools(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("calc", "maths", "Calculator", nil), makeLocalBackend("calc"))
+	mustRegister(t, idx, makeTestTool("calc", "admin", "Admin calculator", nil), makeLocalBackend("admin"))
+	if err := idx.SetHidden("admin:calc", true); err != nil {
+		t.Fatalf("SetHidden failed: %v", err)
+	}
+
+	_, _, candidates, err := idx.GetToolFuzzy("admn:calc")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if len(candidates) != 0 {
+		t.Fatalf("expected no hidden candidates, got %v", candidates)
+	}
+
+	if _, _, _, err := idx.GetToolFuzzy("admin:calc"); err != nil {
+		t.Fatalf("hidden tool must still resolve by exact ID: %v", err)
+	}
+}
+
+func TestBoundedLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		max  int
+		want int
+	}{
+		{"", "", 2, 0},
+		{"calc", "calc", 2, 0},
+		{"math:calc", "maths:calc", 2, 1},
+		{"kitten", "sitting", 3, 3},
+		{"kitten", "sitting", 2, -1},
+		{"a", "abcdef", 3, -1},
+	}
+	for _, tt := range tests {
+		if got := boundedLevenshtein(tt.a, tt.b, tt.max); got != tt.want {
+			t.Errorf("boundedLevenshtein(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestFuzzySearcher(t *testing.T) {
+	idx := NewInMemoryIndex(IndexOptions{Searcher: NewFuzzySearcher(2)})
+	mustRegister(t, idx, makeTestTool("calculator", "math", "Adds numbers", nil), makeLocalBackend("a"))
+	mustRegister(t, idx, makeTestTool("calculate", "stats", "Computes stats", nil), makeLocalBackend("b"))
+	mustRegister(t, idx, makeTestTool("sum", "misc", "Sums", []string{"calculator"}), makeLocalBackend("c"))
+	mustRegister(t, idx, makeTestTool("fetch", "web", "Fetch", nil), makeLocalBackend("d"))
+
+	// "calcualtor" is 2 edits from the calculator name and tag and 3 from
+	// "calculate"; the name match wins the tie.
+	results, err := idx.Search("Calcualtor", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if got, want := summaryIDs(results), []string{"math:calculator", "misc:sum"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	if results, _ := idx.Search("calculatr", 1); len(results) != 1 || results[0].ID != "math:calculator" {
+		t.Fatalf("expected closest match within limit, got %+v", results)
+	}
+	if results, _ := idx.Search("calcualtor", 0); len(results) != 0 {
+		t.Fatalf("expected no results for zero limit, got %+v", results)
+	}
+
+	explanations, _ := idx.SearchExplain("calculatr", 10)
+	if len(explanations) != 3 || explanations[2].Summary.ID != "misc:sum" || explanations[2].Score != 2 {
+		t.Fatalf("unexpected explanations: %+v", explanations)
+	}
+	if _, _, err := idx.SearchPage("calcualtor", 1, ""); err != nil {
+		t.Fatalf("expected fuzzy searcher to satisfy the determinism check: %v", err)
+	}
+}
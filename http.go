@@ -0,0 +1,124 @@
+package toolindex
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/jonwraymond/toolmodel"
+)
+
+// defaultHTTPLimit is the page size used by Handler when no limit is given.
+const defaultHTTPLimit = 50
+
+// Handler returns a read-only JSON HTTP handler over idx. It serves:
+//
+//	GET /tools?limit=&cursor=           paginated tool summaries
+//	GET /tools/{id}                     full tool and default backend
+//	GET /search?q=&limit=&cursor=       paginated search
+//	GET /namespaces?limit=&cursor=      paginated namespaces
+//
+// ErrNotFound maps to 404, ErrInvalidCursor and bad parameters to 400, and
+// other errors to 500. The handler is an optional interop layer; the rest of
+// the package is transport-agnostic.
+func Handler(idx Index) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tools", func(w http.ResponseWriter, r *http.Request) {
+		limit, ok := parseLimit(w, r)
+		if !ok {
+			return
+		}
+		results, next, err := idx.SearchPage("", limit, r.URL.Query().Get("cursor"))
+		if err != nil {
+			writeHTTPError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, summaryPageResponse{Results: results, NextCursor: next})
+	})
+	mux.HandleFunc("GET /tools/{id}", func(w http.ResponseWriter, r *http.Request) {
+		tool, backend, err := idx.GetTool(r.PathValue("id"))
+		if err != nil {
+			writeHTTPError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, toolResponse{Tool: tool, Backend: backend})
+	})
+	mux.HandleFunc("GET /search", func(w http.ResponseWriter, r *http.Request) {
+		limit, ok := parseLimit(w, r)
+		if !ok {
+			return
+		}
+		q := r.URL.Query()
+		results, next, err := idx.SearchPage(q.Get("q"), limit, q.Get("cursor"))
+		if err != nil {
+			writeHTTPError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, summaryPageResponse{Results: results, NextCursor: next})
+	})
+	mux.HandleFunc("GET /namespaces", func(w http.ResponseWriter, r *http.Request) {
+		limit, ok := parseLimit(w, r)
+		if !ok {
+			return
+		}
+		namespaces, next, err := idx.ListNamespacesPage(limit, r.URL.Query().Get("cursor"))
+		if err != nil {
+			writeHTTPError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, namespacePageResponse{Namespaces: namespaces, NextCursor: next})
+	})
+	return mux
+}
+
+type summaryPageResponse struct {
+	Results    []Summary `json:"results"`
+	NextCursor string    `json:"nextCursor,omitempty"`
+}
+
+type namespacePageResponse struct {
+	Namespaces []string `json:"namespaces"`
+	NextCursor string   `json:"nextCursor,omitempty"`
+}
+
+type toolResponse struct {
+	Tool    toolmodel.Tool        `json:"tool"`
+	Backend toolmodel.ToolBackend `json:"backend"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// parseLimit reads the limit query parameter, writing a 400 on bad input.
+func parseLimit(w http.ResponseWriter, r *http.Request) (int, bool) {
+	raw := r.URL.Query().Get("limit")
+	if raw == "" {
+		return defaultHTTPLimit, true
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit <= 0 {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "limit must be a positive integer"})
+		return 0, false
+	}
+	return limit, true
+}
+
+// writeHTTPError maps index errors to HTTP status codes.
+func writeHTTPError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrInvalidCursor), errors.Is(err, ErrInvalidTool), errors.Is(err, ErrInvalidBackend), errors.Is(err, ErrEmptyID):
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
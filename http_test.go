@@ -0,0 +1,82 @@
+package toolindex
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serveHTTP(t *testing.T, h http.Handler, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestHandler_Endpoints(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("add", "math", "Add numbers", nil), makeLocalBackend("add"))
+	mustRegister(t, idx, makeTestTool("fetch", "web", "Fetch a page", nil), makeMCPBackend("web"))
+	h := Handler(idx)
+
+	rec := serveHTTP(t, h, "/tools?limit=1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("/tools status = %d", rec.Code)
+	}
+	var page summaryPageResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+		t.Fatalf("decode /tools: %v", err)
+	}
+	if len(page.Results) != 1 || page.NextCursor == "" {
+		t.Fatalf("unexpected /tools page: %+v", page)
+	}
+
+	rec = serveHTTP(t, h, "/tools/math:add")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("/tools/{id} status = %d", rec.Code)
+	}
+	var tool toolResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &tool); err != nil {
+		t.Fatalf("decode /tools/{id}: %v", err)
+	}
+	if tool.Tool.Name != "add" || tool.Backend.Local == nil {
+		t.Fatalf("unexpected tool response: %+v", tool)
+	}
+
+	rec = serveHTTP(t, h, "/search?q=fetch")
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+		t.Fatalf("decode /search: %v", err)
+	}
+	if len(page.Results) != 1 || page.Results[0].ID != "web:fetch" {
+		t.Fatalf("unexpected /search results: %+v", page)
+	}
+
+	rec = serveHTTP(t, h, "/namespaces")
+	var namespaces namespacePageResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &namespaces); err != nil {
+		t.Fatalf("decode /namespaces: %v", err)
+	}
+	if len(namespaces.Namespaces) != 2 {
+		t.Fatalf("unexpected namespaces: %+v", namespaces)
+	}
+}
+
+func TestHandler_ErrorMapping(t *testing.T) {
+	h := Handler(NewInMemoryIndex())
+
+	tests := []struct {
+		target string
+		want   int
+	}{
+		{"/tools/missing:tool", http.StatusNotFound},
+		{"/search?q=x&cursor=not-base64", http.StatusBadRequest},
+		{"/tools?limit=0", http.StatusBadRequest},
+		{"/namespaces?limit=abc", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if rec := serveHTTP(t, h, tt.target); rec.Code != tt.want {
+			t.Errorf("GET %s status = %d, want %d", tt.target, rec.Code, tt.want)
+		}
+	}
+}
@@ -7,10 +7,20 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"math"
+	"net/url"
+	"path"
+	"reflect"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/jonwraymond/toolmodel"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -19,6 +29,16 @@ import (
 // MaxShortDescriptionLen is the maximum length of the ShortDescription field in Summary.
 const MaxShortDescriptionLen = 120
 
+// DocTextFieldSeparator separates fields in SearchDoc.DocText when
+// IndexOptions.PreserveFieldBoundaries is set. The default searcher matches a
+// trimmed query as a substring of DocText, so a phrase of space-separated
+// words cannot straddle two fields, but a query that itself contains the
+// separator still can. Searchers that match on SearchDoc.Tokens ignore it.
+const DocTextFieldSeparator = "\n"
+
+// toolIDSeparator joins namespace and name in canonical tool IDs (see toolmodel.Tool.ToolID).
+const toolIDSeparator = ":"
+
 // Error values for consistent error handling by callers.
 var (
 	ErrNotFound                 = errors.New("tool not found")
@@ -26,39 +46,102 @@ var (
 	ErrInvalidBackend           = errors.New("invalid backend")
 	ErrInvalidCursor            = errors.New("invalid cursor")
 	ErrNonDeterministicSearcher = errors.New("searcher is non-deterministic")
+	ErrNotReady                 = errors.New("index not ready")
+	ErrNoBackend                = errors.New("no backend selected")
+	ErrEmptyID                  = errors.New("empty tool ID")
+	// ErrConflict reports a tool ID that is already registered, or repeated
+	// in a batch, with different MCP fields. It wraps ErrInvalidTool, so
+	// errors.Is(err, ErrInvalidTool) also holds.
+	ErrConflict = fmt.Errorf("%w: conflicting definition", ErrInvalidTool)
+	// ErrBackendConflict reports a backend identity re-registered with
+	// different details under IndexOptions.StrictBackendReplace. It wraps
+	// ErrInvalidBackend, so errors.Is(err, ErrInvalidBackend) also holds.
+	ErrBackendConflict = fmt.Errorf("%w: conflicting backend details", ErrInvalidBackend)
 )
 
 // Summary represents a lightweight view of a tool for search results.
 // It contains only the essential information for display and discovery,
 // without the full schema payloads.
 type Summary struct {
-	ID               string   `json:"id"`
-	Name             string   `json:"name"`
-	Namespace        string   `json:"namespace,omitempty"`
-	ShortDescription string   `json:"shortDescription,omitempty"`
-	Tags             []string `json:"tags,omitempty"`
+	ID               string `json:"id"`
+	Name             string `json:"name"`
+	Namespace        string `json:"namespace,omitempty"`
+	ShortDescription string `json:"shortDescription,omitempty"`
+	// Preview is a longer description excerpt, populated only when
+	// IndexOptions.PreviewLen is set.
+	Preview string   `json:"preview,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	// Category is the tool's controlled-vocabulary category (see SetCategory).
+	Category string `json:"category,omitempty"`
+	// Deprecated marks tools that remain resolvable but have been superseded.
+	Deprecated        bool   `json:"deprecated,omitempty"`
+	ReplacedBy        string `json:"replacedBy,omitempty"`
+	DeprecationReason string `json:"deprecationReason,omitempty"`
 }
 
 // SearchDoc is the internal/exported struct used by Searcher implementations.
 // It contains precomputed search data for efficient querying.
 type SearchDoc struct {
 	ID      string  // Canonical tool ID
-	DocText string  // Lowercased concatenation of name/namespace/description/tags
+	DocText string  // Lowercased concatenation of name/namespace/description/tags, with _ and - as spaces
 	Summary Summary // Prebuilt summary for fast return
+	// Tokens is DocText split into lowercase word tokens (see Tokenize).
+	// It is precomputed per tool so token-based searchers need not re-split.
+	Tokens []string
+	// Hidden reports whether the tool is hidden from discovery. Hidden docs
+	// are only passed to searchers when SearchFilter.IncludeHidden is set.
+	Hidden bool
+	// Popularity is the usage score set via SetPopularity (zero by default).
+	Popularity float64
+	// HasOutputSchema reports whether the tool declares a non-empty
+	// OutputSchema.
+	HasOutputSchema bool
+	// RegisteredSeq orders tools by first registration: each newly added
+	// tool gets a larger value, and re-registration keeps the original.
+	RegisteredSeq uint64
+	// Fields holds per-field token counts when IndexOptions.WeightedSearchDocs
+	// is set, and is nil otherwise. It is shared between snapshots and must
+	// be treated as read-only.
+	Fields *FieldTokens
+}
+
+// FieldTokens maps each token of a tool's searchable fields to its number of
+// occurrences, one map per field, so searchers can weight fields
+// independently without re-tokenizing. Tokens are produced by Tokenize.
+type FieldTokens struct {
+	Name        map[string]int
+	Namespace   map[string]int
+	Description map[string]int
+	Tags        map[string]int
+	Category    map[string]int
+}
+
+// clone returns a deep copy of f, or nil when f is nil.
+func (f *FieldTokens) clone() *FieldTokens {
+	if f == nil {
+		return nil
+	}
+	return &FieldTokens{
+		Name:        maps.Clone(f.Name),
+		Namespace:   maps.Clone(f.Namespace),
+		Description: maps.Clone(f.Description),
+		Tags:        maps.Clone(f.Tags),
+		Category:    maps.Clone(f.Category),
+	}
 }
 
 // Index defines the interface for a tool registry.
 //
 // Contract:
-// - Concurrency: implementations must be safe for concurrent use.
-// - Errors: validation failures should return ErrInvalidTool/ErrInvalidBackend;
-//   missing tools/backends should return ErrNotFound; cursor issues should return
-//   ErrInvalidCursor; pagination with non-deterministic searchers should return
-//   ErrNonDeterministicSearcher. Callers must use errors.Is.
-// - Ownership: returned slices are caller-owned; elements are read-only and may be shared.
-// - Determinism: Search/List methods must return stable ordering for identical inputs.
-// - Nil/zero: empty inputs are treated as no-ops; SearchPage requires limit > 0.
-// - Atomicity: batch registration is not guaranteed to be atomic on error.
+//   - Concurrency: implementations must be safe for concurrent use.
+//   - Errors: validation failures should return ErrInvalidTool/ErrInvalidBackend;
+//     missing tools/backends should return ErrNotFound; cursor issues should return
+//     ErrInvalidCursor; pagination with non-deterministic searchers should return
+//     ErrNonDeterministicSearcher. Callers must use errors.Is.
+//   - Ownership: returned slices are caller-owned; elements are read-only and may be shared.
+//   - Determinism: Search/List methods must return stable ordering for identical inputs.
+//   - Nil/zero: empty inputs are treated as no-ops; SearchPage requires limit > 0.
+//   - Atomicity: batch registration is not guaranteed to be atomic on error.
 type Index interface {
 	// Registration
 	RegisterTool(tool toolmodel.Tool, backend toolmodel.ToolBackend) error
@@ -71,6 +154,7 @@ type Index interface {
 	// Lookup
 	GetTool(id string) (toolmodel.Tool, toolmodel.ToolBackend, error)
 	GetAllBackends(id string) ([]toolmodel.ToolBackend, error)
+	GetSummary(id string) (Summary, error)
 
 	// Discovery
 	Search(query string, limit int) ([]Summary, error)
@@ -83,6 +167,16 @@ type Index interface {
 type ToolRegistration struct {
 	Tool    toolmodel.Tool
 	Backend toolmodel.ToolBackend
+	// Hidden, when true, hides the tool from discovery (see SetHidden).
+	// False leaves an existing tool's visibility unchanged.
+	Hidden bool
+	// TagsNormalized declares that Tool.Tags is already the output of
+	// toolmodel.NormalizeTags, so registration uses it as-is instead of
+	// normalizing again. See IndexOptions.CheckNormalizedTags.
+	TagsNormalized bool
+	// Category sets the tool's category (see SetCategory). Empty leaves an
+	// existing tool's category unchanged.
+	Category string
 }
 
 // BackendSelector is a function that selects the default backend from a list.
@@ -112,6 +206,44 @@ type DeterministicSearcher interface {
 	Deterministic() bool
 }
 
+// PrebuildSearcher is an optional interface for stateful searchers that build
+// their own structures (for example, an inverted index) from the search docs.
+//
+// Contract:
+//   - Prebuild receives a read-only snapshot of docs and the index version it
+//     reflects; implementations may retain the slice.
+//   - Prebuild must be safe to call concurrently with Search.
+type PrebuildSearcher interface {
+	Searcher
+	Prebuild(docs []SearchDoc, version uint64)
+}
+
+// VersionedSearcher is an optional interface for stateful searchers that
+// cache structures derived from the docs. InMemoryIndex calls SearchVersioned
+// instead of Search, passing the version of the index snapshot the docs were
+// taken from, so the searcher can rebuild only when the version changes.
+//
+// Contract:
+//   - docs may be a filtered subset of the snapshot (see SearchFilter); the
+//     same version can therefore arrive with different doc slices.
+//   - Versions increase monotonically with index mutations.
+type VersionedSearcher interface {
+	Searcher
+	SearchVersioned(query string, limit int, docs []SearchDoc, version uint64) ([]Summary, error)
+}
+
+// PagedSearcher is an optional interface for searchers that paginate natively.
+//
+// Contract:
+//   - SearchPage owns cursor encoding and validation; it should return
+//     ErrInvalidCursor for malformed or stale cursors.
+//   - An empty returned cursor means there are no further results.
+//   - limit is always positive when called by InMemoryIndex.
+type PagedSearcher interface {
+	Searcher
+	SearchPage(query string, limit int, cursor string, docs []SearchDoc) ([]Summary, string, error)
+}
+
 // ChangeType describes a mutation event in the index.
 type ChangeType string
 
@@ -154,6 +286,9 @@ type Refresher interface {
 }
 
 // IndexOptions configures the behavior of an Index implementation.
+// NewInMemoryIndex accepts at most one, as its first option, and panics on a
+// second rather than silently ignoring it; compose extra settings with the
+// With functions instead.
 type IndexOptions struct {
 	BackendSelector BackendSelector
 	Searcher        Searcher
@@ -161,6 +296,119 @@ type IndexOptions struct {
 	// When true, SearchPage returns ErrNonDeterministicSearcher if the configured
 	// searcher does not declare deterministic ordering.
 	RequireDeterministicSearcher *bool
+	// UpstreamLoader, when set, is consulted by GetTool on a local miss.
+	// A successful load registers the tool before returning it, making the
+	// index a lazy cache over a larger catalog. Returning an error wrapping
+	// ErrNotFound reports a genuine miss.
+	UpstreamLoader UpstreamLoader
+	// DocTextAugmenter, when set, receives each tool and its base search doc
+	// text and returns the text to index. It runs whenever a tool's derived
+	// fields are rebuilt, so extra keywords follow re-registration.
+	DocTextAugmenter func(tool toolmodel.Tool, base string) string
+	// OnDocsRebuilt, when set, is called after each search doc cache rebuild
+	// with a copy of the new docs and their version. It runs outside the index
+	// lock; concurrent rebuilds may deliver out of order, so compare versions.
+	// Backend-only mutations leave the docs unchanged and do not trigger it.
+	OnDocsRebuilt func(docs []SearchDoc, version uint64)
+	// SearcherFallback, when set, answers queries whenever the configured
+	// searcher returns an error, so search degrades instead of failing.
+	SearcherFallback Searcher
+	// OnSearcherError, when set, is called with the primary searcher's error
+	// each time SearcherFallback is used.
+	OnSearcherError func(err error)
+	// MaxSearchLimit, when positive, caps the limit of every search: larger
+	// requested limits (including SearchPage page sizes) are clamped to it
+	// before the searcher runs. Zero means no cap.
+	MaxSearchLimit int
+	// PreserveFieldBoundaries joins DocText fields with DocTextFieldSeparator
+	// instead of a space so the default searcher's phrase matches do not span
+	// a field boundary (for example, the end of the name and the start of the
+	// description). See DocTextFieldSeparator for the limits of this.
+	PreserveFieldBoundaries bool
+	// PreserveTagCase shows tags in Summary.Tags as the caller wrote them
+	// (trimmed) instead of normalized. Tags that normalize to the same key
+	// are deduplicated, keeping the first-seen form. Search and filtering
+	// always use the normalized tags.
+	PreserveTagCase bool
+	// SplitCamelCase indexes camelCase tool names and namespaces as separate
+	// words, so "getUserProfile" matches "user profile". Display names are
+	// unchanged.
+	SplitCamelCase bool
+	// WeightedSearchDocs populates SearchDoc.Fields with per-field token
+	// counts for searchers that score name, namespace, description, and tags
+	// separately. It costs extra memory per tool, so it is off by default.
+	WeightedSearchDocs bool
+	// IndexIconText folds text derived from a tool's icons into DocText.
+	// mcp.Icon carries no title or alt text, so the only source is the file
+	// name of URL icons: "https://cdn.example/icons/postgres-logo.svg"
+	// contributes "postgres logo". Data URIs contribute nothing. Off by
+	// default since icon names are rarely meaningful.
+	IndexIconText bool
+	// ProviderIDSeparator separates providerID from toolID in the provider
+	// backendID accepted by UnregisterBackend, HasBackend, and
+	// ToolsUsingBackend, for deployments whose provider IDs contain colons.
+	// It must be a single character; empty means ":". NewInMemoryIndex
+	// panics on any other value.
+	ProviderIDSeparator string
+	// PreviewLen, when positive, populates Summary.Preview with up to that
+	// many characters of the description. Zero leaves Preview empty.
+	PreviewLen int
+	// EmptyQueryReturnsAll controls what the default searcher returns for an
+	// empty or whitespace-only query: every doc (up to the limit) when true,
+	// nothing when false. Defaults to true.
+	EmptyQueryReturnsAll *bool
+	// ScoreFunc, when set, replaces the default searcher's relevance scoring.
+	// It receives the trimmed query, with underscores and hyphens replaced by
+	// spaces and lowercased unless the search sets SearchFilter.CaseSensitive,
+	// and returns a score; zero or less excludes the doc. The default
+	// searcher still applies the deprecation adjustment and sorts pinned
+	// tools first, then by score, then ID.
+	ScoreFunc func(query string, doc SearchDoc) int
+	// PopularityWeight is the fraction of a tool's popularity (see
+	// SetPopularity) that the default searcher adds to the score of matching
+	// docs, rounded to the nearest point. Zero disables the boost.
+	PopularityWeight float64
+	// CheckNormalizedTags verifies tags registered with
+	// ToolRegistration.TagsNormalized against toolmodel.NormalizeTags and
+	// rejects mismatches with ErrInvalidTool. It restores the cost the
+	// trusted path avoids, so enable it in tests and debug builds.
+	CheckNormalizedTags bool
+	// OnDecision, when set, receives a DecisionEvent at each branch point of
+	// registration (new tool, backend added or replaced, rejections, no-ops)
+	// for tracing sync behavior. It is called outside the index lock.
+	OnDecision func(DecisionEvent)
+	// TrackFingerprint maintains the catalog Fingerprint incrementally on
+	// each mutation, making Fingerprint O(1) at the cost of hashing each
+	// changed tool. Without it, Fingerprint hashes every tool on demand.
+	TrackFingerprint bool
+	// StrictBackendReplace makes re-registering a backend identity with
+	// different details fail with ErrBackendConflict instead of silently
+	// replacing the stored backend. Identical re-registrations still succeed.
+	StrictBackendReplace bool
+	// MaxTagsPerTool, when positive, caps the normalized tags indexed for a
+	// tool, keeping the first N. At registration the stored tool's Tags are
+	// unchanged and only search text and Summary.Tags are bounded; tags added
+	// by TagNamespace, TagMatching, or MergeTool beyond the cap are not added
+	// at all. Zero means unlimited.
+	MaxTagsPerTool int
+	// OnTagsTruncated, when set, is called with the tool ID and the dropped
+	// tags whenever MaxTagsPerTool drops tags from a tool. It is called
+	// outside the index lock, only after the change is applied, so rejected
+	// registrations report nothing.
+	OnTagsTruncated func(toolID string, dropped []string)
+	// NameLengthPenalty, when positive, makes the default searcher subtract
+	// this many points per character of the tool name (rounded, and never
+	// enough to drop a match), so shorter names win among equal matches.
+	// Zero disables the penalty.
+	NameLengthPenalty float64
+	// RejectSelfReferentialBackends rejects, with ErrInvalidBackend, provider
+	// backends whose "providerID:toolID" equals the ID of the tool being
+	// registered. Such aliases can make upstream resolution loop forever.
+	RejectSelfReferentialBackends bool
+	// PinnedTools lists tool IDs that the default searcher ranks above other
+	// matches, whatever their scores. Pinned tools only appear when they
+	// match the query and filter.
+	PinnedTools []string
 }
 
 // toolRecord holds all data for a single registered tool.
@@ -170,19 +418,65 @@ type toolRecord struct {
 	backendKeys    map[string]int // maps backend identity key to index in backends slice
 	normalizedTags []string       // normalized tags for search
 	docText        string         // cached search doc text
+	tokens         []string       // cached tokens of docText
 	summary        Summary        // cached summary
+	deprecation    *deprecation   // set by Deprecate; survives re-registration
+	hidden         bool           // excluded from discovery; see SetHidden
+	// backendsVersion is the index version at the last backend-set change.
+	backendsVersion uint64
+	lastSeen        time.Time    // last registration or Touch
+	updatedAt       time.Time    // last registration or content change
+	popularity      float64      // set by SetPopularity; survives re-registration
+	contentHash     uint64       // contribution to idx.fingerprint; see rehashLocked
+	hasOutputSchema bool         // tool declares a non-empty OutputSchema
+	category        string       // set by SetCategory or ToolRegistration.Category
+	seq             uint64       // registration sequence; see SearchDoc.RegisteredSeq
+	fields          *FieldTokens // set when WeightedSearchDocs is enabled
+}
+
+// deprecation holds deprecation metadata for a tool record.
+type deprecation struct {
+	replacedBy string
+	reason     string
 }
 
 // InMemoryIndex is the default in-memory implementation of Index.
 type InMemoryIndex struct {
-	mu              sync.RWMutex
-	tools           map[string]*toolRecord // keyed by tool ID
-	namespaces      map[string]struct{}    // set of namespaces
-	namespaceCounts map[string]int         // number of tools per namespace
-	backendSelector BackendSelector
-	searcher        Searcher
-	listeners       []listenerEntry
-	nextListenerID  uint64
+	mu                            sync.RWMutex
+	tools                         map[string]*toolRecord // keyed by tool ID
+	namespaces                    map[string]struct{}    // set of namespaces
+	namespaceCounts               map[string]int         // number of tools per namespace
+	hiddenCounts                  map[string]int         // number of hidden tools per namespace
+	aliases                       map[string]string      // retired tool ID -> merged target ID; see MergeTool
+	lastSeq                       uint64                 // last registration sequence assigned
+	backendSelector               BackendSelector
+	searcher                      Searcher
+	lexical                       *lexicalSearcher // default searcher, configured from options
+	upstreamLoader                UpstreamLoader
+	upstreamLoads                 loadGroup
+	docTextAugmenter              func(tool toolmodel.Tool, base string) string
+	onDocsRebuilt                 func(docs []SearchDoc, version uint64)
+	previewLen                    int
+	maxSearchLimit                int
+	preserveFieldBoundaries       bool
+	splitCamelCase                bool
+	weightedSearchDocs            bool
+	indexIconText                 bool
+	providerIDSeparator           string
+	preserveTagCase               bool
+	rejectSelfReferentialBackends bool
+	strictBackendReplace          bool
+	checkNormalizedTags           bool
+	trackFingerprint              bool
+	fingerprint                   uint64 // XOR of record content hashes when tracked
+	searcherFallback              Searcher
+	onSearcherError               func(err error)
+	onDecision                    func(DecisionEvent)
+	maxTagsPerTool                int
+	onTagsTruncated               func(toolID string, dropped []string)
+	listeners                     []listenerEntry
+	nextListenerID                uint64
+	now                           func() time.Time // clock for record timestamps
 
 	// Search doc cache
 	searchDocs        []SearchDoc
@@ -190,6 +484,13 @@ type InMemoryIndex struct {
 	searchDocsVersion uint64
 	indexVersion      uint64
 	searchDocsBuilds  int // for test visibility
+	searchDocsPatches int // incremental cache updates, for test visibility
+
+	lastRebuildDuration time.Duration
+	lastRebuildDocCount int
+	snapshotHits        atomic.Uint64 // fast-path cache hits in snapshotSearchDocs
+	snapshotMisses      atomic.Uint64 // slow-path entries in snapshotSearchDocs
+	rebuildRetries      atomic.Uint64 // off-lock rebuilds discarded after a concurrent mutation
 
 	requireDeterministicSearcher bool
 }
@@ -199,27 +500,69 @@ type listenerEntry struct {
 	fn ChangeListener
 }
 
-// NewInMemoryIndex creates a new in-memory tool index.
-func NewInMemoryIndex(opts ...IndexOptions) *InMemoryIndex {
+// NewInMemoryIndex creates a new in-memory tool index. Options apply in
+// order. An IndexOptions struct sets every field at once, so at most one may
+// be passed and it must come first; NewInMemoryIndex panics otherwise rather
+// than silently discarding configuration.
+func NewInMemoryIndex(opts ...Option) *InMemoryIndex {
+	opt := buildOptions(opts)
+	lexical := &lexicalSearcher{}
 	idx := &InMemoryIndex{
 		tools:                        make(map[string]*toolRecord),
 		namespaces:                   make(map[string]struct{}),
 		namespaceCounts:              make(map[string]int),
 		backendSelector:              DefaultBackendSelector,
-		searcher:                     &lexicalSearcher{},
+		searcher:                     lexical,
+		lexical:                      lexical,
+		now:                          time.Now,
 		requireDeterministicSearcher: true,
 	}
 
-	if len(opts) > 0 {
-		opt := opts[0]
-		if opt.BackendSelector != nil {
-			idx.backendSelector = opt.BackendSelector
+	if opt.BackendSelector != nil {
+		idx.backendSelector = opt.BackendSelector
+	}
+	if opt.Searcher != nil {
+		idx.searcher = opt.Searcher
+	}
+	if opt.RequireDeterministicSearcher != nil {
+		idx.requireDeterministicSearcher = *opt.RequireDeterministicSearcher
+	}
+	idx.upstreamLoader = opt.UpstreamLoader
+	idx.docTextAugmenter = opt.DocTextAugmenter
+	idx.onDocsRebuilt = opt.OnDocsRebuilt
+	idx.previewLen = opt.PreviewLen
+	idx.maxSearchLimit = opt.MaxSearchLimit
+	idx.preserveFieldBoundaries = opt.PreserveFieldBoundaries
+	idx.splitCamelCase = opt.SplitCamelCase
+	idx.weightedSearchDocs = opt.WeightedSearchDocs
+	idx.indexIconText = opt.IndexIconText
+	idx.providerIDSeparator = ":"
+	if opt.ProviderIDSeparator != "" {
+		if utf8.RuneCountInString(opt.ProviderIDSeparator) != 1 {
+			panic(fmt.Sprintf("toolindex: ProviderIDSeparator must be a single character, got %q", opt.ProviderIDSeparator))
 		}
-		if opt.Searcher != nil {
-			idx.searcher = opt.Searcher
-		}
-		if opt.RequireDeterministicSearcher != nil {
-			idx.requireDeterministicSearcher = *opt.RequireDeterministicSearcher
+		idx.providerIDSeparator = opt.ProviderIDSeparator
+	}
+	idx.preserveTagCase = opt.PreserveTagCase
+	idx.rejectSelfReferentialBackends = opt.RejectSelfReferentialBackends
+	idx.strictBackendReplace = opt.StrictBackendReplace
+	idx.checkNormalizedTags = opt.CheckNormalizedTags
+	idx.trackFingerprint = opt.TrackFingerprint
+	idx.searcherFallback = opt.SearcherFallback
+	idx.onSearcherError = opt.OnSearcherError
+	idx.onDecision = opt.OnDecision
+	idx.maxTagsPerTool = opt.MaxTagsPerTool
+	idx.onTagsTruncated = opt.OnTagsTruncated
+	lexical.scoreFunc = opt.ScoreFunc
+	lexical.popularityWeight = opt.PopularityWeight
+	lexical.nameLengthPenalty = opt.NameLengthPenalty
+	if opt.EmptyQueryReturnsAll != nil {
+		lexical.emptyQueryNone = !*opt.EmptyQueryReturnsAll
+	}
+	if len(opt.PinnedTools) > 0 {
+		lexical.pinned = make(map[string]struct{}, len(opt.PinnedTools))
+		for _, id := range opt.PinnedTools {
+			lexical.pinned[id] = struct{}{}
 		}
 	}
 
@@ -261,12 +604,92 @@ func (idx *InMemoryIndex) Refresh() uint64 {
 	idx.rebuildSearchDocsLocked()
 	version := idx.indexVersion
 	listeners := idx.snapshotListenersLocked()
+	rebuilt := idx.docsRebuiltSnapshotLocked()
 	idx.mu.Unlock()
 
+	idx.notifyDocsRebuilt(rebuilt, version)
 	notifyListeners(listeners, ChangeEvent{Type: ChangeRefreshed, Version: version})
 	return version
 }
 
+// SetSearcher swaps the searcher used for subsequent queries. Passing nil
+// restores the default lexical searcher. Searches already in flight finish
+// against the searcher they started with. If s implements PrebuildSearcher,
+// it is fed the current search docs before SetSearcher returns.
+func (idx *InMemoryIndex) SetSearcher(s Searcher) {
+	idx.mu.Lock()
+	if s == nil {
+		s = idx.lexical
+	}
+	idx.searcher = s
+	idx.mu.Unlock()
+
+	if ps, ok := s.(PrebuildSearcher); ok {
+		docs, version := idx.snapshotSearchDocs()
+		ps.Prebuild(docs, version)
+	}
+}
+
+// DefaultSearcher returns the built-in lexical searcher, configured from the
+// index options, whether or not it is the active searcher. Use it to compose
+// the default ranking with other searchers, for example via MultiSearcher.
+func (idx *InMemoryIndex) DefaultSearcher() Searcher {
+	return idx.lexical
+}
+
+// Warmup builds the search docs cache and, if the active searcher implements
+// PrebuildSearcher, feeds it the docs, so the first query does not pay for
+// either. Call it once after the initial registrations and before serving
+// traffic. It returns the index version the warm structures reflect.
+func (idx *InMemoryIndex) Warmup() uint64 {
+	searcher := idx.activeSearcher()
+	docs, version := idx.snapshotSearchDocs()
+	if ps, ok := searcher.(PrebuildSearcher); ok {
+		ps.Prebuild(docs, version)
+	}
+	return version
+}
+
+// SetBackendSelector swaps the policy used to choose default backends.
+// Passing nil restores DefaultBackendSelector. Lookups observe either the old
+// or the new selector, never a mix, because selection happens under the lock.
+func (idx *InMemoryIndex) SetBackendSelector(sel BackendSelector) {
+	if sel == nil {
+		sel = DefaultBackendSelector
+	}
+	idx.mu.Lock()
+	idx.backendSelector = sel
+	idx.mu.Unlock()
+}
+
+// activeSearcher returns the currently configured searcher.
+func (idx *InMemoryIndex) activeSearcher() Searcher {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.searcher
+}
+
+// Ready reports whether the index is usable. It checks that a searcher and
+// backend selector are configured and that a trivial search succeeds.
+// It is cheap enough to back a readiness probe.
+func (idx *InMemoryIndex) Ready() error {
+	idx.mu.RLock()
+	searcher := idx.searcher
+	selector := idx.backendSelector
+	idx.mu.RUnlock()
+
+	if searcher == nil {
+		return fmt.Errorf("%w: no searcher configured", ErrNotReady)
+	}
+	if selector == nil {
+		return fmt.Errorf("%w: no backend selector configured", ErrNotReady)
+	}
+	if _, err := idx.Search("", 1); err != nil {
+		return fmt.Errorf("%w: %v", ErrNotReady, err)
+	}
+	return nil
+}
+
 // DefaultBackendSelector implements the default priority: local > provider > mcp.
 // Exported so other modules (for example, toolrun) can match the same policy.
 func DefaultBackendSelector(backends []toolmodel.ToolBackend) toolmodel.ToolBackend {
@@ -313,6 +736,28 @@ func backendIdentity(backend toolmodel.ToolBackend) string {
 	return ""
 }
 
+// backendKeyFor builds the backend identity key for a kind and the backendID
+// form UnregisterBackend accepts: the server name for MCP, the name for local,
+// and providerID, sep, toolID for provider backends. Unknown kinds yield an
+// empty key, which matches no backend.
+func backendKeyFor(kind toolmodel.BackendKind, backendID, sep string) (string, error) {
+	switch kind {
+	case toolmodel.BackendKindMCP, toolmodel.BackendKindLocal:
+		return encodeIdentity(string(kind), backendID), nil
+	case toolmodel.BackendKindProvider:
+		// Validate backendID format for provider backends
+		if !strings.Contains(backendID, sep) {
+			return "", fmt.Errorf("%w: provider backendID must be in format 'providerID%stoolID'", ErrInvalidBackend, sep)
+		}
+		parts := strings.SplitN(backendID, sep, 2)
+		if parts[0] == "" || parts[1] == "" {
+			return "", fmt.Errorf("%w: provider backendID must have non-empty providerID and toolID", ErrInvalidBackend)
+		}
+		return encodeIdentity(string(kind), parts[0], parts[1]), nil
+	}
+	return "", nil
+}
+
 // encodeIdentity builds an unambiguous identity string using length-prefixed parts.
 // This prevents collisions when fields include separators like ":".
 func encodeIdentity(parts ...string) string {
@@ -328,6 +773,15 @@ func encodeIdentity(parts ...string) string {
 	return b.String()
 }
 
+// formatToolID builds the canonical tool ID for a namespace and name.
+// It matches toolmodel.Tool.ToolID and is the single place the index formats IDs.
+func formatToolID(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + toolIDSeparator + name
+}
+
 // validateBackend checks if a backend is valid.
 func validateBackend(backend toolmodel.ToolBackend) error {
 	switch backend.Kind {
@@ -355,6 +809,15 @@ func validateBackend(backend toolmodel.ToolBackend) error {
 	return nil
 }
 
+// isSelfReferentialBackend reports whether backend is a provider backend whose
+// "providerID:toolID" alias equals the index ID of the tool it serves.
+func isSelfReferentialBackend(toolID string, backend toolmodel.ToolBackend) bool {
+	if backend.Kind != toolmodel.BackendKindProvider || backend.Provider == nil {
+		return false
+	}
+	return formatToolID(backend.Provider.ProviderID, backend.Provider.ToolID) == toolID
+}
+
 // toolMCPFieldsEqual compares the MCP-spec fields of two tools for equivalence.
 // It compares all MCP Tool fields:
 // - Name, Title, Description (string fields)
@@ -405,6 +868,19 @@ func toolMCPFieldsEqual(a, b toolmodel.Tool) bool {
 	return true
 }
 
+// schemaEmpty reports whether a schema is absent or unusable: nil, JSON null,
+// an empty object, or a value that does not marshal.
+func schemaEmpty(schema any) bool {
+	if schema == nil {
+		return true
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return true
+	}
+	return string(data) == "null" || string(data) == "{}"
+}
+
 // jsonEqual compares two interface{} values for JSON-structural equality.
 // Handles json.RawMessage, []byte, maps, slices, and primitive types.
 func jsonEqual(a, b any) bool {
@@ -631,43 +1107,112 @@ func (idx *InMemoryIndex) removeNamespaceLocked(namespace string) {
 	idx.namespaceCounts[namespace] = count - 1
 }
 
+// lookupLocked returns the record for id, following an alias left by
+// MergeTool when no tool is registered under id itself.
+// Must be called with idx.mu held.
+func (idx *InMemoryIndex) lookupLocked(id string) (*toolRecord, bool) {
+	if record, ok := idx.tools[id]; ok {
+		return record, true
+	}
+	if target, ok := idx.aliases[id]; ok {
+		record, ok := idx.tools[target]
+		return record, ok
+	}
+	return nil, false
+}
+
+// nextSeqLocked returns the registration sequence for a newly added tool.
+// Must be called with idx.mu held.
+func (idx *InMemoryIndex) nextSeqLocked() uint64 {
+	idx.lastSeq++
+	return idx.lastSeq
+}
+
+// removeRecordLocked deletes a tool record and its namespace bookkeeping.
+// Must be called with idx.mu held.
+func (idx *InMemoryIndex) removeRecordLocked(toolID string, record *toolRecord) {
+	idx.unhashLocked(record)
+	idx.setHiddenLocked(record, false)
+	delete(idx.tools, toolID)
+	idx.removeNamespaceLocked(record.tool.Namespace)
+}
+
 // RegisterTool registers a single tool with its backend.
 func (idx *InMemoryIndex) RegisterTool(tool toolmodel.Tool, backend toolmodel.ToolBackend) error {
-	// Validate tool
-	if err := tool.Validate(); err != nil {
-		return fmt.Errorf("%w: %v", ErrInvalidTool, err)
-	}
+	return idx.registerTool(tool, backend, registerOptions{})
+}
 
-	// Validate backend
-	if err := validateBackend(backend); err != nil {
+// registerOptions carries per-registration settings beyond the tool and backend.
+type registerOptions struct {
+	hidden         bool   // hide the tool from discovery (never un-hides)
+	tagsNormalized bool   // trust tool.Tags as already normalized
+	category       string // category to assign; empty keeps the current one
+}
+
+// registerTool implements RegisterTool with additional per-registration options.
+func (idx *InMemoryIndex) registerTool(tool toolmodel.Tool, backend toolmodel.ToolBackend, opts registerOptions) error {
+	if err := validateIndexTool(tool); err != nil {
+		return err
+	}
+	toolID := formatToolID(tool.Namespace, tool.Name)
+	if err := idx.validateToolBackend(toolID, backend); err != nil {
 		return err
 	}
-
-	toolID := tool.ToolID()
 	backendKey := backendIdentity(backend)
-	normalizedTags := toolmodel.NormalizeTags(tool.Tags)
+	normalizedTags, droppedTags, err := idx.ingestTags(toolID, tool.Tags, opts.tagsNormalized)
+	if err != nil {
+		return err
+	}
+	category, err := normalizeCategory(toolID, opts.category)
+	if err != nil {
+		return err
+	}
+
+	var decisions []DecisionEvent
+	decide := func(kind DecisionType, detail string) {
+		if idx.onDecision != nil {
+			decisions = append(decisions, DecisionEvent{Type: kind, ToolID: toolID, Backend: backend, Detail: detail})
+		}
+	}
 
 	idx.mu.Lock()
 
 	record, exists := idx.tools[toolID]
 	changeType := ChangeRegistered
+	var docBefore SearchDoc
 	if !exists {
 		record = &toolRecord{
 			tool:           tool,
 			backends:       []toolmodel.ToolBackend{backend},
 			backendKeys:    map[string]int{backendKey: 0},
 			normalizedTags: normalizedTags,
+			category:       category,
+			seq:            idx.nextSeqLocked(),
 		}
-		refreshRecordDerived(record)
+		idx.refreshRecordDerived(record)
 		idx.tools[toolID] = record
 		idx.addNamespaceLocked(tool.Namespace)
+		delete(idx.aliases, toolID)
+		decide(DecisionToolAdded, "new tool")
 	} else {
 		changeType = ChangeUpdated
 		// Check MCP field consistency: new tool's MCP fields must match existing
 		if !toolMCPFieldsEqual(record.tool, tool) {
 			idx.mu.Unlock()
-			return fmt.Errorf("%w: tool %q MCP fields differ from existing registration", ErrInvalidTool, toolID)
+			decide(DecisionFieldsMismatch, "MCP fields differ from existing registration")
+			idx.emitDecisions(decisions)
+			return fmt.Errorf("%w: tool %q MCP fields differ from existing registration", ErrConflict, toolID)
+		}
+
+		existingIdx, replacing := record.backendKeys[backendKey]
+		if replacing && idx.strictBackendReplace && !reflect.DeepEqual(record.backends[existingIdx], backend) {
+			idx.mu.Unlock()
+			decide(DecisionBackendConflict, "backend identity exists with different details")
+			idx.emitDecisions(decisions)
+			return fmt.Errorf("%w: tool %q already has a %s backend with this identity but different details", ErrBackendConflict, toolID, backend.Kind)
 		}
+		noOp := replacing && reflect.DeepEqual(record.backends[existingIdx], backend) && slices.Equal(record.normalizedTags, normalizedTags)
+		docBefore = searchDocFor(toolID, record)
 
 		// Track namespace changes if tool is re-registered under a new namespace.
 		if record.tool.Namespace != tool.Namespace {
@@ -678,24 +1223,47 @@ func (idx *InMemoryIndex) RegisterTool(tool toolmodel.Tool, backend toolmodel.To
 		// Update toolmodel extensions (Tags) - these are allowed to differ
 		record.tool = tool
 		record.normalizedTags = normalizedTags
-		refreshRecordDerived(record)
+		if category != "" {
+			record.category = category
+		}
+		idx.refreshRecordDerived(record)
 
-		// Check if backend already exists
-		if existingIdx, ok := record.backendKeys[backendKey]; ok {
+		if replacing {
 			// Replace existing backend
 			record.backends[existingIdx] = backend
+			if noOp {
+				decide(DecisionNoOp, "identical tool, tags, and backend")
+			} else {
+				decide(DecisionBackendReplaced, "backend identity already registered")
+			}
 		} else {
 			// Add new backend
 			record.backendKeys[backendKey] = len(record.backends)
 			record.backends = append(record.backends, backend)
+			decide(DecisionBackendAdded, "new backend identity")
 		}
 	}
 
-	idx.markSearchDocsDirtyLocked()
+	if opts.hidden {
+		idx.setHiddenLocked(record, true)
+	}
+	idx.rehashLocked(record)
+
+	if exists && reflect.DeepEqual(docBefore, searchDocFor(toolID, record)) {
+		// Only backends changed, e.g. a second backend was added.
+		idx.bumpVersionLocked()
+	} else {
+		idx.updateSearchDocsLocked(toolID)
+	}
+	record.backendsVersion = idx.indexVersion
+	record.lastSeen = idx.now()
+	record.updatedAt = record.lastSeen
 	version := idx.indexVersion
 	listeners := idx.snapshotListenersLocked()
 	idx.mu.Unlock()
 
+	idx.emitDecisions(decisions)
+	idx.reportTruncatedTags(toolID, droppedTags)
 	notifyListeners(listeners, ChangeEvent{
 		Type:    changeType,
 		ToolID:  toolID,
@@ -705,16 +1273,214 @@ func (idx *InMemoryIndex) RegisterTool(tool toolmodel.Tool, backend toolmodel.To
 	return nil
 }
 
+// validateIndexTool checks that a tool is valid and can be indexed.
+// toolmodel already rejects the separator in names, so rejecting it in
+// namespaces makes tool IDs unambiguous: "a:b:x" can never be formed by both
+// namespace "a:b" with name "x" and namespace "a" with name "b:x".
+func validateIndexTool(tool toolmodel.Tool) error {
+	if err := tool.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTool, err)
+	}
+	if strings.Contains(tool.Namespace, toolIDSeparator) {
+		return fmt.Errorf("%w: namespace %q must not contain the tool ID separator %q", ErrInvalidTool, tool.Namespace, toolIDSeparator)
+	}
+	return nil
+}
+
+// validateToolBackend checks a backend for the tool toolID, including the
+// self-reference guard when configured.
+func (idx *InMemoryIndex) validateToolBackend(toolID string, backend toolmodel.ToolBackend) error {
+	if err := validateBackend(backend); err != nil {
+		return err
+	}
+	if idx.rejectSelfReferentialBackends && isSelfReferentialBackend(toolID, backend) {
+		return fmt.Errorf("%w: provider backend %s:%s refers to tool %q itself", ErrInvalidBackend, backend.Provider.ProviderID, backend.Provider.ToolID, toolID)
+	}
+	return nil
+}
+
+// ingestTags returns the tags to index for a registration: normalized (or
+// trusted as normalized when tagsNormalized is set) and then capped.
+// Must be called without idx.mu held.
+func (idx *InMemoryIndex) ingestTags(toolID string, tags []string, tagsNormalized bool) (kept, dropped []string, err error) {
+	var normalizedTags []string
+	if tagsNormalized {
+		normalizedTags = slices.Clone(tags)
+		if idx.checkNormalizedTags && !slices.Equal(toolmodel.NormalizeTags(tags), normalizedTags) {
+			return nil, nil, fmt.Errorf("%w: tool %q tags %q are not normalized", ErrInvalidTool, toolID, tags)
+		}
+	} else {
+		normalizedTags = toolmodel.NormalizeTags(tags)
+	}
+	kept, dropped = idx.capTags(normalizedTags)
+	return kept, dropped, nil
+}
+
+// capTags applies IndexOptions.MaxTagsPerTool to a tool's normalized tags,
+// returning the tags to keep and the ones dropped. Callers report dropped
+// tags with reportTruncatedTags once their mutation has been applied.
+func (idx *InMemoryIndex) capTags(tags []string) (kept, dropped []string) {
+	if idx.maxTagsPerTool <= 0 || len(tags) <= idx.maxTagsPerTool {
+		return tags, nil
+	}
+	return slices.Clip(tags[:idx.maxTagsPerTool]), slices.Clone(tags[idx.maxTagsPerTool:])
+}
+
+// tagRoomLocked reports whether record can gain another tag under
+// IndexOptions.MaxTagsPerTool.
+// Must be called with idx.mu held.
+func (idx *InMemoryIndex) tagRoomLocked(record *toolRecord) bool {
+	return idx.maxTagsPerTool <= 0 || len(record.normalizedTags) < idx.maxTagsPerTool
+}
+
+// reportTruncatedTags passes tags dropped by MaxTagsPerTool to the
+// OnTagsTruncated hook.
+// Must be called without idx.mu held.
+func (idx *InMemoryIndex) reportTruncatedTags(toolID string, dropped []string) {
+	if idx.onTagsTruncated != nil && len(dropped) > 0 {
+		idx.onTagsTruncated(toolID, dropped)
+	}
+}
+
+// UpsertTool sets the complete state of a tool in one atomic step: its
+// definition, tags, and backend set replace whatever was registered before,
+// without the MCP-field consistency check RegisterTool applies. Index-side
+// curation (deprecation, visibility, popularity) is kept. Backends sharing an
+// identity collapse to the last one. Listeners receive a single
+// ChangeRegistered or ChangeUpdated event.
+func (idx *InMemoryIndex) UpsertTool(tool toolmodel.Tool, backends []toolmodel.ToolBackend) error {
+	if err := validateIndexTool(tool); err != nil {
+		return err
+	}
+	if len(backends) == 0 {
+		return fmt.Errorf("%w: tool requires at least one backend", ErrInvalidBackend)
+	}
+	toolID := formatToolID(tool.Namespace, tool.Name)
+	keys := make(map[string]int, len(backends))
+	deduped := make([]toolmodel.ToolBackend, 0, len(backends))
+	for _, backend := range backends {
+		if err := idx.validateToolBackend(toolID, backend); err != nil {
+			return err
+		}
+		key := backendIdentity(backend)
+		if i, ok := keys[key]; ok {
+			deduped[i] = backend
+			continue
+		}
+		keys[key] = len(deduped)
+		deduped = append(deduped, backend)
+	}
+	normalizedTags, droppedTags := idx.capTags(toolmodel.NormalizeTags(tool.Tags))
+
+	idx.mu.Lock()
+	record, exists := idx.tools[toolID]
+	changeType := ChangeUpdated
+	if !exists {
+		changeType = ChangeRegistered
+		record = &toolRecord{seq: idx.nextSeqLocked()}
+		idx.tools[toolID] = record
+		idx.addNamespaceLocked(tool.Namespace)
+		delete(idx.aliases, toolID)
+	}
+	record.tool = tool
+	record.backends = deduped
+	record.backendKeys = keys
+	record.normalizedTags = normalizedTags
+	idx.refreshRecordDerived(record)
+	idx.rehashLocked(record)
+
+	idx.markSearchDocsDirtyLocked()
+	record.backendsVersion = idx.indexVersion
+	record.lastSeen = idx.now()
+	record.updatedAt = record.lastSeen
+	version := idx.indexVersion
+	listeners := idx.snapshotListenersLocked()
+	idx.mu.Unlock()
+
+	idx.reportTruncatedTags(toolID, droppedTags)
+	notifyListeners(listeners, ChangeEvent{Type: changeType, ToolID: toolID, Version: version})
+	return nil
+}
+
 // RegisterTools registers multiple tools in batch.
+// Before mutating anything, the batch is checked for entries that share a
+// tool ID but disagree on MCP fields; such batches are rejected whole.
+// Entries that share an ID and agree on MCP fields merge their backends.
 func (idx *InMemoryIndex) RegisterTools(regs []ToolRegistration) error {
+	if err := checkBatchConflicts(regs); err != nil {
+		return err
+	}
 	for _, reg := range regs {
-		if err := idx.RegisterTool(reg.Tool, reg.Backend); err != nil {
+		opts := registerOptions{hidden: reg.Hidden, tagsNormalized: reg.TagsNormalized, category: reg.Category}
+		if err := idx.registerTool(reg.Tool, reg.Backend, opts); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// RegistrationFailure describes one entry RegisterToolsPartial could not
+// register.
+type RegistrationFailure struct {
+	// Index is the entry's position in the batch.
+	Index  int
+	ToolID string
+	// Sentinel classifies the failure for routing: ErrInvalidTool when the
+	// tool itself was rejected (including ErrConflict), ErrInvalidBackend
+	// when its backend was (including ErrBackendConflict), and nil for any
+	// other error.
+	Sentinel error
+	// Err is the full error; errors.Is(Err, Sentinel) holds.
+	Err error
+}
+
+// RegisterToolsPartial registers each entry of regs independently, skipping
+// the ones that fail instead of stopping at the first. It returns a failure
+// record per skipped entry, in batch order, or nil when all succeeded.
+// Entries that share a tool ID but disagree on MCP fields are checked against
+// whichever registered first, so the later one fails with ErrConflict.
+func (idx *InMemoryIndex) RegisterToolsPartial(regs []ToolRegistration) []RegistrationFailure {
+	var failures []RegistrationFailure
+	for i, reg := range regs {
+		opts := registerOptions{hidden: reg.Hidden, tagsNormalized: reg.TagsNormalized, category: reg.Category}
+		err := idx.registerTool(reg.Tool, reg.Backend, opts)
+		if err == nil {
+			continue
+		}
+		failure := RegistrationFailure{
+			Index:  i,
+			ToolID: formatToolID(reg.Tool.Namespace, reg.Tool.Name),
+			Err:    err,
+		}
+		switch {
+		case errors.Is(err, ErrInvalidTool):
+			failure.Sentinel = ErrInvalidTool
+		case errors.Is(err, ErrInvalidBackend):
+			failure.Sentinel = ErrInvalidBackend
+		}
+		failures = append(failures, failure)
+	}
+	return failures
+}
+
+// checkBatchConflicts reports the first pair of registrations in regs that
+// share a tool ID but have incompatible MCP fields.
+func checkBatchConflicts(regs []ToolRegistration) error {
+	first := make(map[string]int, len(regs))
+	for i, reg := range regs {
+		id := formatToolID(reg.Tool.Namespace, reg.Tool.Name)
+		j, seen := first[id]
+		if !seen {
+			first[id] = i
+			continue
+		}
+		if !toolMCPFieldsEqual(regs[j].Tool, reg.Tool) {
+			return fmt.Errorf("%w: batch entries %d and %d register tool %q with different MCP fields", ErrConflict, j, i, id)
+		}
+	}
+	return nil
+}
+
 // RegisterToolsFromMCP is a convenience method for registering tools from an MCP server.
 func (idx *InMemoryIndex) RegisterToolsFromMCP(serverName string, tools []toolmodel.Tool) error {
 	backend := toolmodel.ToolBackend{
@@ -732,45 +1498,30 @@ func (idx *InMemoryIndex) RegisterToolsFromMCP(serverName string, tools []toolmo
 
 // UnregisterBackend removes a specific backend from a tool.
 // If the last backend is removed, the tool is also removed.
+// An empty toolID returns ErrEmptyID rather than ErrNotFound. An alias kept by
+// MergeTool resolves to the merged tool, as in GetTool.
 //
-// For provider backends, backendID must be in the format "providerID:toolID".
+// For provider backends, backendID must be in the format "providerID:toolID",
+// split at the first IndexOptions.ProviderIDSeparator.
 // For MCP backends, backendID is the server name.
 // For local backends, backendID is the handler name.
 func (idx *InMemoryIndex) UnregisterBackend(toolID string, kind toolmodel.BackendKind, backendID string) error {
-	var providerID string
-	var providerToolID string
-
-	// Validate backendID format for provider backends
-	if kind == toolmodel.BackendKindProvider {
-		if !strings.Contains(backendID, ":") {
-			return fmt.Errorf("%w: provider backendID must be in format 'providerID:toolID'", ErrInvalidBackend)
-		}
-		parts := strings.SplitN(backendID, ":", 2)
-		if parts[0] == "" || parts[1] == "" {
-			return fmt.Errorf("%w: provider backendID must have non-empty providerID and toolID", ErrInvalidBackend)
-		}
-		providerID = parts[0]
-		providerToolID = parts[1]
+	if toolID == "" {
+		return ErrEmptyID
+	}
+	searchKey, err := backendKeyFor(kind, backendID, idx.providerIDSeparator)
+	if err != nil {
+		return err
 	}
 
 	idx.mu.Lock()
 
-	record, exists := idx.tools[toolID]
+	record, exists := idx.lookupLocked(toolID)
 	if !exists {
 		idx.mu.Unlock()
 		return fmt.Errorf("%w: %s", ErrNotFound, toolID)
 	}
-
-	// Build the backend key to find
-	var searchKey string
-	switch kind {
-	case toolmodel.BackendKindMCP:
-		searchKey = encodeIdentity(string(kind), backendID)
-	case toolmodel.BackendKindProvider:
-		searchKey = encodeIdentity(string(kind), providerID, providerToolID)
-	case toolmodel.BackendKindLocal:
-		searchKey = encodeIdentity(string(kind), backendID)
-	}
+	toolID = formatToolID(record.tool.Namespace, record.tool.Name) // resolve aliases
 
 	// Find and remove the backend
 	foundIdx := -1
@@ -802,13 +1553,16 @@ func (idx *InMemoryIndex) UnregisterBackend(toolID string, kind toolmodel.Backen
 	// If no backends left, remove the tool entirely
 	changeType := ChangeBackendRemoved
 	if len(record.backends) == 0 {
-		namespace := record.tool.Namespace
-		delete(idx.tools, toolID)
-		idx.removeNamespaceLocked(namespace)
+		idx.removeRecordLocked(toolID, record)
 		changeType = ChangeToolRemoved
+		idx.updateSearchDocsLocked(toolID)
+	} else {
+		idx.rehashLocked(record)
+		idx.bumpVersionLocked()
 	}
 
-	idx.markSearchDocsDirtyLocked()
+	record.backendsVersion = idx.indexVersion
+	record.updatedAt = idx.now()
 	version := idx.indexVersion
 	listeners := idx.snapshotListenersLocked()
 	idx.mu.Unlock()
@@ -823,25 +1577,78 @@ func (idx *InMemoryIndex) UnregisterBackend(toolID string, kind toolmodel.Backen
 }
 
 // GetTool returns the full tool and its default backend.
+// When an UpstreamLoader is configured, a local miss is resolved through it.
+// An empty id returns ErrEmptyID rather than ErrNotFound.
 func (idx *InMemoryIndex) GetTool(id string) (toolmodel.Tool, toolmodel.ToolBackend, error) {
+	if id == "" {
+		return toolmodel.Tool{}, toolmodel.ToolBackend{}, ErrEmptyID
+	}
+	tool, backend, err := idx.getToolLocal(id)
+	if errors.Is(err, ErrNotFound) && idx.upstreamLoader != nil {
+		return idx.loadFromUpstream(id)
+	}
+	return tool, backend, err
+}
+
+// getToolLocal resolves a tool from the in-memory records only.
+func (idx *InMemoryIndex) getToolLocal(id string) (toolmodel.Tool, toolmodel.ToolBackend, error) {
+	return idx.getToolLocalWith(id, nil)
+}
+
+// GetToolWith is like GetTool but picks the default backend with selector
+// for this call only, leaving the index-wide selector unchanged. A nil
+// selector uses the configured one.
+func (idx *InMemoryIndex) GetToolWith(id string, selector BackendSelector) (toolmodel.Tool, toolmodel.ToolBackend, error) {
+	if id == "" {
+		return toolmodel.Tool{}, toolmodel.ToolBackend{}, ErrEmptyID
+	}
+	tool, backend, err := idx.getToolLocalWith(id, selector)
+	if errors.Is(err, ErrNotFound) && idx.upstreamLoader != nil {
+		if _, _, err := idx.loadFromUpstream(id); err != nil {
+			return toolmodel.Tool{}, toolmodel.ToolBackend{}, err
+		}
+		return idx.getToolLocalWith(id, selector)
+	}
+	return tool, backend, err
+}
+
+// getToolLocalWith resolves a tool from the in-memory records only, using
+// selector (or the configured selector when nil) to pick the backend.
+func (idx *InMemoryIndex) getToolLocalWith(id string, selector BackendSelector) (toolmodel.Tool, toolmodel.ToolBackend, error) {
 	idx.mu.RLock()
 	defer idx.mu.RUnlock()
 
-	record, exists := idx.tools[id]
+	record, exists := idx.lookupLocked(id)
 	if !exists {
 		return toolmodel.Tool{}, toolmodel.ToolBackend{}, fmt.Errorf("%w: %s", ErrNotFound, id)
 	}
 
-	defaultBackend := idx.backendSelector(record.backends)
+	if selector == nil {
+		selector = idx.backendSelector
+	}
+	defaultBackend, ok := SelectBackend(selector, record.backends)
+	if !ok {
+		return toolmodel.Tool{}, toolmodel.ToolBackend{}, fmt.Errorf("%w: %s", ErrNoBackend, id)
+	}
 	return record.tool, defaultBackend, nil
 }
 
+// GetToolByParts returns the full tool and its default backend for the given
+// namespace and name, building the ID with the same format the index uses.
+func (idx *InMemoryIndex) GetToolByParts(namespace, name string) (toolmodel.Tool, toolmodel.ToolBackend, error) {
+	return idx.GetTool(formatToolID(namespace, name))
+}
+
 // GetAllBackends returns all backends for a tool.
+// An empty id returns ErrEmptyID rather than ErrNotFound.
 func (idx *InMemoryIndex) GetAllBackends(id string) ([]toolmodel.ToolBackend, error) {
+	if id == "" {
+		return nil, ErrEmptyID
+	}
 	idx.mu.RLock()
 	defer idx.mu.RUnlock()
 
-	record, exists := idx.tools[id]
+	record, exists := idx.lookupLocked(id)
 	if !exists {
 		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
 	}
@@ -852,88 +1659,418 @@ func (idx *InMemoryIndex) GetAllBackends(id string) ([]toolmodel.ToolBackend, er
 	return result, nil
 }
 
-// Search performs a search over the indexed tools.
-func (idx *InMemoryIndex) Search(query string, limit int) ([]Summary, error) {
-	docs, _ := idx.snapshotSearchDocs()
-	return idx.searcher.Search(query, limit, docs)
-}
-
-// SearchPage performs a search over the indexed tools with cursor pagination.
-func (idx *InMemoryIndex) SearchPage(query string, limit int, cursor string) ([]Summary, string, error) {
-	if limit <= 0 {
-		return nil, "", fmt.Errorf("limit must be positive")
+// ResolveTool returns the tool, its default backend, and a copy of all its
+// backends, read atomically under a single lock so the default is always one
+// of the returned backends. Like GetTool, a local miss is resolved through
+// the UpstreamLoader when one is configured.
+func (idx *InMemoryIndex) ResolveTool(id string) (toolmodel.Tool, toolmodel.ToolBackend, []toolmodel.ToolBackend, error) {
+	if id == "" {
+		return toolmodel.Tool{}, toolmodel.ToolBackend{}, nil, ErrEmptyID
+	}
+	tool, backend, backends, err := idx.resolveToolLocal(id)
+	if errors.Is(err, ErrNotFound) && idx.upstreamLoader != nil {
+		if _, _, err := idx.loadFromUpstream(id); err != nil {
+			return toolmodel.Tool{}, toolmodel.ToolBackend{}, nil, err
+		}
+		return idx.resolveToolLocal(id)
 	}
+	return tool, backend, backends, err
+}
 
-	docs, version := idx.snapshotSearchDocs()
+// resolveToolLocal implements ResolveTool against the in-memory records only.
+func (idx *InMemoryIndex) resolveToolLocal(id string) (toolmodel.Tool, toolmodel.ToolBackend, []toolmodel.ToolBackend, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
 
-	if idx.requireDeterministicSearcher {
-		if ds, ok := idx.searcher.(DeterministicSearcher); !ok || !ds.Deterministic() {
-			return nil, "", ErrNonDeterministicSearcher
-		}
-	}
-	results, err := idx.searcher.Search(query, len(docs), docs)
-	if err != nil {
-		return nil, "", err
+	record, exists := idx.lookupLocked(id)
+	if !exists {
+		return toolmodel.Tool{}, toolmodel.ToolBackend{}, nil, fmt.Errorf("%w: %s", ErrNotFound, id)
 	}
 
-	page, nextCursor, err := paginateResults(results, limit, cursor, version)
-	if err != nil {
-		return nil, "", err
+	defaultBackend, ok := SelectBackend(idx.backendSelector, record.backends)
+	if !ok {
+		return toolmodel.Tool{}, toolmodel.ToolBackend{}, nil, fmt.Errorf("%w: %s", ErrNoBackend, id)
 	}
-	return page, nextCursor, nil
+	return record.tool, defaultBackend, slices.Clone(record.backends), nil
 }
 
-// ensureSearchDocsLocked rebuilds the search docs cache if dirty.
-// Must be called with idx.mu held.
-func (idx *InMemoryIndex) ensureSearchDocsLocked() {
-	if !idx.searchDocsDirty && idx.searchDocs != nil && idx.searchDocsVersion == idx.indexVersion {
-		return
+// GetSummary returns the cached summary of a tool, the same view Search
+// returns, without the full tool or backend selection. Hidden tools resolve.
+// When an UpstreamLoader is configured, a local miss is resolved through it,
+// as in GetTool. The returned Summary is a copy the caller may modify.
+func (idx *InMemoryIndex) GetSummary(id string) (Summary, error) {
+	if id == "" {
+		return Summary{}, ErrEmptyID
+	}
+	summary, err := idx.getSummaryLocal(id)
+	if errors.Is(err, ErrNotFound) && idx.upstreamLoader != nil {
+		if _, _, err := idx.loadFromUpstream(id); err != nil {
+			return Summary{}, err
+		}
+		return idx.getSummaryLocal(id)
 	}
-	idx.rebuildSearchDocsLocked()
+	return summary, err
 }
 
-func (idx *InMemoryIndex) snapshotSearchDocs() ([]SearchDoc, uint64) {
-	// Fast path: serve a cached snapshot under a read lock.
+// getSummaryLocal resolves a summary from the in-memory records only.
+func (idx *InMemoryIndex) getSummaryLocal(id string) (Summary, error) {
 	idx.mu.RLock()
-	if !idx.searchDocsDirty && idx.searchDocs != nil && idx.searchDocsVersion == idx.indexVersion {
-		docs := make([]SearchDoc, len(idx.searchDocs))
-		copy(docs, idx.searchDocs)
-		version := idx.searchDocsVersion
+	defer idx.mu.RUnlock()
+
+	record, exists := idx.lookupLocked(id)
+	if !exists {
+		return Summary{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	summary := record.summary
+	summary.Tags = slices.Clone(summary.Tags)
+	return summary, nil
+}
+
+// GetBackendsPage returns a tool's backends sorted by backend identity with
+// cursor pagination. Cursors are scoped to the tool's backend set: any backend
+// addition, replacement, or removal invalidates them with ErrInvalidCursor.
+func (idx *InMemoryIndex) GetBackendsPage(toolID string, limit int, cursor string) ([]toolmodel.ToolBackend, string, error) {
+	if limit <= 0 {
+		return nil, "", fmt.Errorf("limit must be positive")
+	}
+
+	idx.mu.RLock()
+	record, exists := idx.lookupLocked(toolID)
+	if !exists {
 		idx.mu.RUnlock()
+		return nil, "", fmt.Errorf("%w: %s", ErrNotFound, toolID)
+	}
+	backends := make([]toolmodel.ToolBackend, len(record.backends))
+	copy(backends, record.backends)
+	version := record.backendsVersion
+	idx.mu.RUnlock()
+
+	sort.Slice(backends, func(i, j int) bool {
+		return backendIdentity(backends[i]) < backendIdentity(backends[j])
+	})
+	return paginateResults(backends, limit, cursor, version)
+}
+
+// GetBackendsRanked returns all backends for a tool ordered by the configured
+// backend selector's preference; the first element is what GetTool returns.
+func (idx *InMemoryIndex) GetBackendsRanked(toolID string) ([]toolmodel.ToolBackend, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	record, exists := idx.lookupLocked(toolID)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, toolID)
+	}
+	return rankBackends(idx.backendSelector, record.backends), nil
+}
+
+// ToolBackendRef pairs a backend with the ID of the tool it serves.
+type ToolBackendRef struct {
+	ToolID  string
+	Backend toolmodel.ToolBackend
+}
+
+// HasBackend reports whether a tool has the given backend registered, using
+// the same identity rules and backendID form as UnregisterBackend. It returns
+// ErrNotFound only when the tool itself is missing; an absent backend yields
+// false with a nil error.
+func (idx *InMemoryIndex) HasBackend(toolID string, kind toolmodel.BackendKind, backendID string) (bool, error) {
+	if toolID == "" {
+		return false, ErrEmptyID
+	}
+	key, err := backendKeyFor(kind, backendID, idx.providerIDSeparator)
+	if err != nil {
+		return false, err
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	record, exists := idx.lookupLocked(toolID)
+	if !exists {
+		return false, fmt.Errorf("%w: %s", ErrNotFound, toolID)
+	}
+	_, ok := record.backendKeys[key]
+	return ok, nil
+}
+
+// ToolsUsingBackend returns the sorted IDs of every tool, hidden or not, that
+// has the given backend registered, for answering "what breaks if this
+// backend goes away". backendID takes the same form as in UnregisterBackend.
+// Provider IDs not in "providerID:toolID" form (see ProviderIDSeparator) and
+// unknown kinds return ErrInvalidBackend.
+func (idx *InMemoryIndex) ToolsUsingBackend(kind toolmodel.BackendKind, backendID string) ([]string, error) {
+	key, err := backendKeyFor(kind, backendID, idx.providerIDSeparator)
+	if err != nil {
+		return nil, err
+	}
+	if key == "" {
+		return nil, fmt.Errorf("%w: unknown backend kind %q", ErrInvalidBackend, kind)
+	}
+
+	idx.mu.RLock()
+	ids := []string{}
+	for id, record := range idx.tools {
+		if _, ok := record.backendKeys[key]; ok {
+			ids = append(ids, id)
+		}
+	}
+	idx.mu.RUnlock()
+
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// ListAllBackends returns every (tool, backend) pair in the index, including
+// hidden tools, sorted by tool ID and then backend identity, with cursor
+// pagination. Any index mutation invalidates outstanding cursors.
+func (idx *InMemoryIndex) ListAllBackends(limit int, cursor string) ([]ToolBackendRef, string, error) {
+	if limit <= 0 {
+		return nil, "", fmt.Errorf("limit must be positive")
+	}
+
+	idx.mu.RLock()
+	var refs []ToolBackendRef
+	for id, record := range idx.tools {
+		for _, backend := range record.backends {
+			refs = append(refs, ToolBackendRef{ToolID: id, Backend: backend})
+		}
+	}
+	version := idx.indexVersion
+	idx.mu.RUnlock()
+
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].ToolID != refs[j].ToolID {
+			return refs[i].ToolID < refs[j].ToolID
+		}
+		return backendIdentity(refs[i].Backend) < backendIdentity(refs[j].Backend)
+	})
+	return paginateResults(refs, limit, cursor, version)
+}
+
+// Search performs a search over the indexed tools.
+func (idx *InMemoryIndex) Search(query string, limit int) ([]Summary, error) {
+	return idx.SearchFiltered(query, limit, SearchFilter{})
+}
+
+// SearchPage performs a search over the indexed tools with cursor pagination.
+func (idx *InMemoryIndex) SearchPage(query string, limit int, cursor string) ([]Summary, string, error) {
+	return idx.SearchPageFiltered(query, limit, cursor, SearchFilter{})
+}
+
+// ensureSearchDocsLocked rebuilds the search docs cache if dirty.
+// Must be called with idx.mu held.
+func (idx *InMemoryIndex) ensureSearchDocsLocked() {
+	if idx.searchDocsFreshLocked() {
+		return
+	}
+	idx.rebuildSearchDocsLocked()
+}
+
+// searchDocsFreshLocked reports whether the search docs cache reflects the
+// current index version.
+// Must be called with idx.mu held (read or write).
+func (idx *InMemoryIndex) searchDocsFreshLocked() bool {
+	return !idx.searchDocsDirty && idx.searchDocs != nil && idx.searchDocsVersion == idx.indexVersion
+}
+
+// maxRebuildAttempts bounds how many times snapshotSearchDocs builds docs
+// outside the write lock before falling back to building under it.
+const maxRebuildAttempts = 3
+
+func (idx *InMemoryIndex) snapshotSearchDocs() ([]SearchDoc, uint64) {
+	return idx.snapshotSearchDocsWith(nil)
+}
+
+// snapshotSearchDocsWith is snapshotSearchDocs that also runs underLock, if
+// non-nil, while idx.mu is held, so callers can read other state consistent
+// with the returned docs and version.
+func (idx *InMemoryIndex) snapshotSearchDocsWith(underLock func()) ([]SearchDoc, uint64) {
+	// Fast path: serve a cached snapshot under a read lock.
+	idx.mu.RLock()
+	if idx.searchDocsFreshLocked() {
+		docs := make([]SearchDoc, len(idx.searchDocs))
+		copy(docs, idx.searchDocs)
+		version := idx.searchDocsVersion
+		if underLock != nil {
+			underLock()
+		}
+		idx.mu.RUnlock()
+		idx.snapshotHits.Add(1)
 		return docs, version
 	}
 	idx.mu.RUnlock()
+	idx.snapshotMisses.Add(1)
+
+	// Slow path: build the docs from the records under a read lock, sort them
+	// with no lock held, and install them under a brief exclusive lock if no
+	// mutation landed in between. Readers are never blocked by the build.
+	// After maxRebuildAttempts lost races, build under the write lock so a
+	// steady stream of writes cannot starve the rebuild.
+	var builds int
+	for attempt := 1; ; attempt++ {
+		if attempt > maxRebuildAttempts {
+			idx.mu.Lock()
+			builds = idx.searchDocsBuilds
+			idx.ensureSearchDocsLocked()
+			break
+		}
 
-	// Slow path: rebuild the cache under an exclusive lock.
-	idx.mu.Lock()
-	idx.ensureSearchDocsLocked()
+		idx.mu.RLock()
+		start := time.Now()
+		builtVersion := idx.indexVersion
+		built := idx.collectSearchDocsLocked()
+		idx.mu.RUnlock()
+		sortSearchDocs(built)
+
+		idx.mu.Lock()
+		builds = idx.searchDocsBuilds
+		if idx.searchDocsFreshLocked() {
+			break // another caller installed a current cache meanwhile
+		}
+		if builtVersion == idx.indexVersion {
+			idx.installSearchDocsLocked(built, start)
+			break
+		}
+		idx.mu.Unlock()
+		idx.rebuildRetries.Add(1)
+	}
 	docs := make([]SearchDoc, len(idx.searchDocs))
 	copy(docs, idx.searchDocs)
 	version := idx.searchDocsVersion
+	if underLock != nil {
+		underLock()
+	}
+	var rebuilt []SearchDoc
+	if idx.searchDocsBuilds != builds {
+		rebuilt = idx.docsRebuiltSnapshotLocked()
+	}
 	idx.mu.Unlock()
 
+	idx.notifyDocsRebuilt(rebuilt, version)
 	return docs, version
 }
 
+// docsRebuiltSnapshotLocked returns a deep copy of the search docs for the
+// OnDocsRebuilt hook, or nil when no hook is configured.
+// Must be called with idx.mu held.
+func (idx *InMemoryIndex) docsRebuiltSnapshotLocked() []SearchDoc {
+	if idx.onDocsRebuilt == nil {
+		return nil
+	}
+	docs := make([]SearchDoc, len(idx.searchDocs))
+	copy(docs, idx.searchDocs)
+	for i := range docs {
+		docs[i].Summary.Tags = slices.Clone(docs[i].Summary.Tags)
+		docs[i].Tokens = slices.Clone(docs[i].Tokens)
+		docs[i].Fields = docs[i].Fields.clone()
+	}
+	return docs
+}
+
+// notifyDocsRebuilt invokes the OnDocsRebuilt hook outside the index lock.
+func (idx *InMemoryIndex) notifyDocsRebuilt(docs []SearchDoc, version uint64) {
+	if idx.onDocsRebuilt == nil || docs == nil {
+		return
+	}
+	idx.onDocsRebuilt(docs, version)
+}
+
 // rebuildSearchDocsLocked rebuilds the search docs from scratch.
 // Must be called with idx.mu held.
 func (idx *InMemoryIndex) rebuildSearchDocsLocked() {
+	start := time.Now()
+	docs := idx.collectSearchDocsLocked()
+	sortSearchDocs(docs)
+	idx.installSearchDocsLocked(docs, start)
+}
+
+// collectSearchDocsLocked builds an unsorted search doc for every record.
+// Must be called with idx.mu held (read or write).
+func (idx *InMemoryIndex) collectSearchDocsLocked() []SearchDoc {
 	docs := make([]SearchDoc, 0, len(idx.tools))
 	for id, record := range idx.tools {
-		docs = append(docs, SearchDoc{
-			ID:      id,
-			DocText: record.docText,
-			Summary: record.summary,
-		})
+		docs = append(docs, searchDocFor(id, record))
+	}
+	return docs
+}
+
+// searchDocFor builds the search doc for one record.
+func searchDocFor(id string, record *toolRecord) SearchDoc {
+	return SearchDoc{
+		ID:              id,
+		DocText:         record.docText,
+		Summary:         record.summary,
+		Tokens:          record.tokens,
+		Hidden:          record.hidden,
+		Popularity:      record.popularity,
+		HasOutputSchema: record.hasOutputSchema,
+		RegisteredSeq:   record.seq,
+		Fields:          record.fields,
 	}
-	// Sort by ID for deterministic order
+}
+
+// sortSearchDocs sorts docs by ID for deterministic order.
+func sortSearchDocs(docs []SearchDoc) {
 	sort.Slice(docs, func(i, j int) bool {
 		return docs[i].ID < docs[j].ID
 	})
+}
+
+// installSearchDocsLocked makes docs, built at the current index version
+// starting at start, the search docs cache.
+// Must be called with idx.mu held.
+func (idx *InMemoryIndex) installSearchDocsLocked(docs []SearchDoc, start time.Time) {
 	idx.searchDocs = docs
 	idx.searchDocsDirty = false
 	idx.searchDocsVersion = idx.indexVersion
 	idx.searchDocsBuilds++
+	idx.lastRebuildDuration = time.Since(start)
+	idx.lastRebuildDocCount = len(docs)
+}
+
+// updateSearchDocsLocked records a mutation of the given tools. When the
+// search doc cache is current, each tool's doc is replaced, inserted at its
+// sorted position, or removed if the tool is gone, instead of the whole
+// cache being marked for rebuild. With an OnDocsRebuilt hook configured the
+// cache is always rebuilt, so the hook still sees every change.
+// Must be called with idx.mu held.
+func (idx *InMemoryIndex) updateSearchDocsLocked(toolIDs ...string) {
+	patch := idx.searchDocsFreshLocked() && idx.onDocsRebuilt == nil
+	idx.markSearchDocsDirtyLocked()
+	if !patch {
+		return
+	}
+	for _, id := range toolIDs {
+		i, found := slices.BinarySearchFunc(idx.searchDocs, id, func(doc SearchDoc, id string) int {
+			return strings.Compare(doc.ID, id)
+		})
+		record, exists := idx.tools[id]
+		switch {
+		case exists && found:
+			idx.searchDocs[i] = searchDocFor(id, record)
+		case exists:
+			idx.searchDocs = slices.Insert(idx.searchDocs, i, searchDocFor(id, record))
+		case found:
+			idx.searchDocs = slices.Delete(idx.searchDocs, i, i+1)
+		}
+	}
+	idx.searchDocsDirty = false
+	idx.searchDocsVersion = idx.indexVersion
+	idx.searchDocsPatches++
+}
+
+// bumpVersionLocked records a mutation that leaves every search doc unchanged,
+// such as adding or removing one of several backends. The index version
+// advances, but a current cache stays current, so nothing is rebuilt and
+// OnDocsRebuilt does not fire.
+// Must be called with idx.mu held.
+func (idx *InMemoryIndex) bumpVersionLocked() {
+	fresh := idx.searchDocsFreshLocked()
+	idx.indexVersion++
+	if fresh {
+		idx.searchDocsVersion = idx.indexVersion
+	}
 }
 
 // markSearchDocsDirtyLocked marks the search docs cache as stale.
@@ -961,16 +2098,26 @@ func notifyListeners(listeners []ChangeListener, event ChangeEvent) {
 }
 
 // ListNamespaces returns all namespaces in alphabetical order.
+// Namespaces whose tools are all hidden are omitted.
 func (idx *InMemoryIndex) ListNamespaces() ([]string, error) {
 	idx.mu.RLock()
 	defer idx.mu.RUnlock()
 
+	result := idx.visibleNamespacesLocked()
+	sort.Strings(result)
+	return result, nil
+}
+
+// visibleNamespacesLocked returns namespaces with at least one visible tool.
+// Must be called with idx.mu held.
+func (idx *InMemoryIndex) visibleNamespacesLocked() []string {
 	result := make([]string, 0, len(idx.namespaces))
 	for ns := range idx.namespaces {
-		result = append(result, ns)
+		if idx.namespaceCounts[ns] > idx.hiddenCounts[ns] {
+			result = append(result, ns)
+		}
 	}
-	sort.Strings(result)
-	return result, nil
+	return result
 }
 
 // ListNamespacesPage returns namespaces with cursor pagination.
@@ -981,10 +2128,7 @@ func (idx *InMemoryIndex) ListNamespacesPage(limit int, cursor string) ([]string
 
 	idx.mu.RLock()
 	version := idx.indexVersion
-	result := make([]string, 0, len(idx.namespaces))
-	for ns := range idx.namespaces {
-		result = append(result, ns)
-	}
+	result := idx.visibleNamespacesLocked()
 	idx.mu.RUnlock()
 
 	sort.Strings(result)
@@ -995,21 +2139,360 @@ func (idx *InMemoryIndex) ListNamespacesPage(limit int, cursor string) ([]string
 	return page, nextCursor, nil
 }
 
+// ListToolIDs returns the sorted IDs of every registered tool, including
+// hidden tools. It is the cheapest enumeration, intended for diffing the
+// index against an external system.
+func (idx *InMemoryIndex) ListToolIDs() []string {
+	idx.mu.RLock()
+	ids := make([]string, 0, len(idx.tools))
+	for id := range idx.tools {
+		ids = append(ids, id)
+	}
+	idx.mu.RUnlock()
+
+	sort.Strings(ids)
+	return ids
+}
+
+// ListToolIDsPage is ListToolIDs with cursor pagination. Any index mutation
+// invalidates outstanding cursors.
+func (idx *InMemoryIndex) ListToolIDsPage(limit int, cursor string) ([]string, string, error) {
+	if limit <= 0 {
+		return nil, "", fmt.Errorf("limit must be positive")
+	}
+
+	idx.mu.RLock()
+	version := idx.indexVersion
+	ids := make([]string, 0, len(idx.tools))
+	for id := range idx.tools {
+		ids = append(ids, id)
+	}
+	idx.mu.RUnlock()
+
+	sort.Strings(ids)
+	return paginateResults(ids, limit, cursor, version)
+}
+
+// ListFilter constrains which tools ListTools returns. The zero value
+// matches every visible tool.
+type ListFilter struct {
+	// Namespace, when set, restricts results to tools in that namespace.
+	Namespace string
+	// Tags, when non-empty, restricts results to tools carrying every listed
+	// tag. Entries are compared after normalization.
+	Tags []string
+	// BackendKind, when set, restricts results to tools with at least one
+	// backend of that kind.
+	BackendKind toolmodel.BackendKind
+	// IncludeHidden includes tools hidden via SetHidden.
+	IncludeHidden bool
+}
+
+// ListTools returns the summaries of the tools that pass filter, sorted by
+// ID. Unlike an empty-query Search it has no limit and no ranking, for admin
+// tooling that enumerates the catalog. An unknown BackendKind returns
+// ErrInvalidBackend.
+func (idx *InMemoryIndex) ListTools(filter ListFilter) ([]Summary, error) {
+	summaries, _, err := idx.listTools(filter)
+	return summaries, err
+}
+
+// ListToolsPage is ListTools with cursor pagination, for sync jobs that walk
+// the catalog in batches. Any index mutation invalidates outstanding cursors
+// with ErrInvalidCursor, as with SearchPage.
+func (idx *InMemoryIndex) ListToolsPage(filter ListFilter, limit int, cursor string) ([]Summary, string, error) {
+	if limit <= 0 {
+		return nil, "", fmt.Errorf("limit must be positive")
+	}
+	summaries, version, err := idx.listTools(filter)
+	if err != nil {
+		return nil, "", err
+	}
+	return paginateResults(summaries, limit, cursor, version)
+}
+
+// listTools implements ListTools, also returning the index version the
+// summaries reflect.
+func (idx *InMemoryIndex) listTools(filter ListFilter) ([]Summary, uint64, error) {
+	switch filter.BackendKind {
+	case "", toolmodel.BackendKindMCP, toolmodel.BackendKindProvider, toolmodel.BackendKindLocal:
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown backend kind %q", ErrInvalidBackend, filter.BackendKind)
+	}
+	tags := toolmodel.NormalizeTags(filter.Tags)
+
+	idx.mu.RLock()
+	version := idx.indexVersion
+	summaries := []Summary{}
+	if len(filter.Tags) > 0 && len(tags) == 0 {
+		idx.mu.RUnlock()
+		return summaries, version, nil // no listed tag can match
+	}
+	for _, record := range idx.tools {
+		if record.hidden && !filter.IncludeHidden {
+			continue
+		}
+		if filter.Namespace != "" && record.tool.Namespace != filter.Namespace {
+			continue
+		}
+		if !containsAll(record.normalizedTags, tags) {
+			continue
+		}
+		if filter.BackendKind != "" && !slices.ContainsFunc(record.backends, func(b toolmodel.ToolBackend) bool {
+			return b.Kind == filter.BackendKind
+		}) {
+			continue
+		}
+		summary := record.summary
+		summary.Tags = slices.Clone(summary.Tags)
+		summaries = append(summaries, summary)
+	}
+	idx.mu.RUnlock()
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].ID < summaries[j].ID
+	})
+	return summaries, version, nil
+}
+
+// containsAll reports whether have contains every element of want.
+func containsAll(have, want []string) bool {
+	for _, w := range want {
+		if !slices.Contains(have, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// NamespaceCount pairs a namespace with its number of visible tools.
+type NamespaceCount struct {
+	Namespace string
+	Count     int
+}
+
+// NamespaceCountOptions configures NamespaceCounts.
+type NamespaceCountOptions struct {
+	// ByCount orders namespaces by descending count, then name, instead of
+	// alphabetically.
+	ByCount bool
+}
+
+// NamespaceCounts returns every namespace with visible tools and how many it
+// has, in one consistent read. Hidden tools are not counted, matching
+// ListNamespaces. Namespaces are sorted alphabetically unless ByCount is set.
+func (idx *InMemoryIndex) NamespaceCounts(opts ...NamespaceCountOptions) []NamespaceCount {
+	var opt NamespaceCountOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	idx.mu.RLock()
+	result := make([]NamespaceCount, 0, len(idx.namespaceCounts))
+	for ns, count := range idx.namespaceCounts {
+		if visible := count - idx.hiddenCounts[ns]; visible > 0 {
+			result = append(result, NamespaceCount{Namespace: ns, Count: visible})
+		}
+	}
+	idx.mu.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if opt.ByCount && result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Namespace < result[j].Namespace
+	})
+	return result
+}
+
+// Count returns the number of registered tools, including hidden ones.
+func (idx *InMemoryIndex) Count() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.tools)
+}
+
+// NamespaceCount returns the number of distinct namespaces, including those
+// whose tools are all hidden.
+func (idx *InMemoryIndex) NamespaceCount() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.namespaces)
+}
+
+// CountByNamespace returns the number of tools, including hidden ones,
+// registered under namespace. The comparison is exact.
+func (idx *InMemoryIndex) CountByNamespace(namespace string) int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.namespaceCounts[namespace]
+}
+
+// HasNamespace reports whether any tool, hidden or not, is registered under
+// namespace. The comparison is exact.
+func (idx *InMemoryIndex) HasNamespace(namespace string) bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	_, ok := idx.namespaces[namespace]
+	return ok
+}
+
 // refreshRecordDerived recomputes cached derived fields for a tool record.
-func refreshRecordDerived(record *toolRecord) {
-	record.docText = buildDocText(record.tool, record.normalizedTags)
+func (idx *InMemoryIndex) refreshRecordDerived(record *toolRecord) {
+	opts := docTextOptions{separator: " ", splitCamelCase: idx.splitCamelCase}
+	if idx.preserveFieldBoundaries {
+		opts.separator = DocTextFieldSeparator
+	}
+	record.docText = buildDocText(record.tool, record.normalizedTags, opts)
+	if record.category != "" {
+		record.docText += opts.separator + normalizeSeparators(record.category)
+	}
+	if idx.indexIconText {
+		if text := iconText(record.tool.Icons); text != "" {
+			record.docText += opts.separator + text
+		}
+	}
+	if idx.docTextAugmenter != nil {
+		record.docText = idx.docTextAugmenter(record.tool, record.docText)
+	}
+	record.tokens = Tokenize(record.docText)
+	record.fields = nil
+	if idx.weightedSearchDocs {
+		record.fields = buildFieldTokens(record.tool, record.normalizedTags, opts)
+		record.fields.Category = countTokens(record.category)
+	}
+	record.hasOutputSchema = !schemaEmpty(record.tool.OutputSchema)
 	record.summary = buildSummary(record.tool, record.normalizedTags)
+	record.summary.Category = record.category
+	if idx.preserveTagCase {
+		record.summary.Tags = displayTags(record.tool.Tags, record.normalizedTags)
+	}
+	if idx.previewLen > 0 {
+		record.summary.Preview = truncateRunes(record.tool.Description, idx.previewLen)
+	}
+	if record.deprecation != nil {
+		record.summary.Deprecated = true
+		record.summary.ReplacedBy = record.deprecation.replacedBy
+		record.summary.DeprecationReason = record.deprecation.reason
+	}
+}
+
+// isQualifiedQuery reports whether query names namespace and name together,
+// in either order, separated by whitespace, '.', ':', or '/'.
+func isQualifiedQuery(query, namespace, name string) bool {
+	terms := strings.FieldsFunc(query, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '.' || r == ':' || r == '/'
+	})
+	if len(terms) < 2 {
+		return false
+	}
+	joined := strings.Join(terms, " ")
+	return joined == namespace+" "+name || joined == name+" "+namespace
+}
+
+// Tokenize splits text into lowercase tokens on any rune that is not a letter
+// or digit. It is the tokenizer used to populate SearchDoc.Tokens.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// buildFieldTokens computes the per-field token counts for a tool. Names and
+// namespaces are split the same way buildDocText splits them.
+func buildFieldTokens(tool toolmodel.Tool, normalizedTags []string, opts docTextOptions) *FieldTokens {
+	name, namespace := tool.Name, tool.Namespace
+	if opts.splitCamelCase {
+		name, namespace = splitCamelCase(name), splitCamelCase(namespace)
+	}
+	return &FieldTokens{
+		Name:        countTokens(name),
+		Namespace:   countTokens(namespace),
+		Description: countTokens(tool.Description),
+		Tags:        countTokens(strings.Join(normalizedTags, " ")),
+	}
+}
+
+// countTokens tokenizes text and counts each token's occurrences.
+func countTokens(text string) map[string]int {
+	counts := make(map[string]int)
+	for _, token := range Tokenize(text) {
+		counts[token]++
+	}
+	return counts
+}
+
+// docTextOptions controls how buildDocText assembles search text.
+type docTextOptions struct {
+	separator      string // joins fields and individual tags
+	splitCamelCase bool   // split camelCase names into words
 }
 
 // buildDocText creates the lowercased search text for a tool.
-func buildDocText(tool toolmodel.Tool, normalizedTags []string) string {
+func buildDocText(tool toolmodel.Tool, normalizedTags []string, opts docTextOptions) string {
+	name, namespace := tool.Name, tool.Namespace
+	if opts.splitCamelCase {
+		name, namespace = splitCamelCase(name), splitCamelCase(namespace)
+	}
 	parts := []string{
-		strings.ToLower(tool.Name),
-		strings.ToLower(tool.Namespace),
+		strings.ToLower(name),
+		strings.ToLower(namespace),
 		strings.ToLower(tool.Description),
 	}
 	parts = append(parts, normalizedTags...) // already normalized/lowercased
-	return strings.Join(parts, " ")
+	for i, part := range parts {
+		parts[i] = normalizeSeparators(part)
+	}
+	return strings.Join(parts, opts.separator)
+}
+
+// iconText returns the lowercased words of the file names of URL icons, for
+// IndexOptions.IndexIconText.
+func iconText(icons []mcp.Icon) string {
+	var words []string
+	for _, icon := range icons {
+		u, err := url.Parse(icon.Source)
+		if err != nil || u.Scheme == "data" {
+			continue
+		}
+		base := path.Base(u.Path)
+		base = strings.TrimSuffix(base, path.Ext(base))
+		if base == "." || base == "/" || base == "" {
+			continue
+		}
+		words = append(words, Tokenize(base)...)
+	}
+	return strings.Join(words, " ")
+}
+
+// splitCamelCase inserts a space at each camelCase word boundary, so
+// "getUserProfile" becomes "get User Profile" and "HTTPServer" becomes
+// "HTTP Server".
+func splitCamelCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	b.Grow(len(s) + 4)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// wordSeparators maps the separators common in machine-style names
+// ("get_user", "get-user") to spaces so they match "get user".
+var wordSeparators = strings.NewReplacer("_", " ", "-", " ")
+
+// normalizeSeparators replaces underscores and hyphens with spaces.
+func normalizeSeparators(s string) string {
+	return wordSeparators.Replace(s)
 }
 
 // buildSummary creates a Summary from tool fields and normalized tags.
@@ -1020,7 +2503,7 @@ func buildSummary(tool toolmodel.Tool, normalizedTags []string) Summary {
 	}
 
 	return Summary{
-		ID:               tool.ToolID(),
+		ID:               formatToolID(tool.Namespace, tool.Name),
 		Name:             tool.Name,
 		Namespace:        tool.Namespace,
 		ShortDescription: shortDesc,
@@ -1028,86 +2511,245 @@ func buildSummary(tool toolmodel.Tool, normalizedTags []string) Summary {
 	}
 }
 
+// displayTags returns the trimmed, original-case form of each raw tag that
+// survived normalization, keeping only the first-seen form per normalized
+// key so "GitHub" and "github" do not both appear.
+func displayTags(raw, normalizedTags []string) []string {
+	keep := make(map[string]bool, len(normalizedTags))
+	for _, tag := range normalizedTags {
+		keep[tag] = true
+	}
+	out := make([]string, 0, len(normalizedTags))
+	for _, tag := range raw {
+		key := toolmodel.NormalizeTags([]string{tag})
+		if len(key) == 0 || !keep[key[0]] {
+			continue
+		}
+		keep[key[0]] = false // first form wins
+		out = append(out, strings.TrimSpace(tag))
+	}
+	return out
+}
+
+// truncateRunes returns at most n runes of s.
+func truncateRunes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	count := 0
+	for i := range s {
+		if count == n {
+			return s[:i]
+		}
+		count++
+	}
+	return s
+}
+
 // lexicalSearcher is the default search implementation using simple lexical matching.
-type lexicalSearcher struct{}
+type lexicalSearcher struct {
+	pinned    map[string]struct{}                   // tool IDs ranked ahead of unpinned matches
+	scoreFunc func(query string, doc SearchDoc) int // replaces the built-in relevance scoring when set
+	// caseSensitive matches names and namespaces without lowercasing and
+	// skips the lowercased DocText fallback; see SearchFilter.CaseSensitive.
+	caseSensitive bool
+	// emptyQueryNone returns no results, rather than every doc, for a query
+	// that is empty after trimming.
+	emptyQueryNone bool
+	// popularityWeight scales SearchDoc.Popularity into bonus points.
+	popularityWeight float64
+	// nameLengthPenalty is subtracted per rune of the tool name.
+	nameLengthPenalty float64
+}
 
 // Deterministic reports whether this searcher returns stable ordering.
 func (s *lexicalSearcher) Deterministic() bool {
 	return true
 }
 
-// scoredResult holds a result with its score for ranking.
-type scoredResult struct {
-	summary Summary
-	score   int
+// prefixBonus is added when the tool name or a tag starts with the query,
+// so "calc" ranks "calculator" above "miscalc-helper" for autocomplete.
+const prefixBonus = 25
+
+// anyTag reports whether match holds for any tag, compared like DocText,
+// and the lowercased query; match is strings.Contains or strings.HasPrefix.
+func anyTag(tags []string, query string, match func(s, query string) bool) bool {
+	for _, tag := range tags {
+		if match(strings.ToLower(normalizeSeparators(tag)), query) {
+			return true
+		}
+	}
+	return false
 }
 
+// deprecatedPenalty is subtracted from the score of matching deprecated tools.
+const deprecatedPenalty = 5
+
 func (s *lexicalSearcher) Search(query string, limit int, docs []SearchDoc) ([]Summary, error) {
+	ranked := s.rank(query, limit, docs, false)
+	results := make([]Summary, len(ranked))
+	for i, r := range ranked {
+		results[i] = r.Summary
+	}
+	return results, nil
+}
+
+// searchIDs ranks like Search and returns only the result IDs.
+func (s *lexicalSearcher) searchIDs(query string, limit int, docs []SearchDoc) []string {
+	ranked := s.rank(query, limit, docs, false)
+	ids := make([]string, len(ranked))
+	for i, r := range ranked {
+		ids[i] = r.Summary.ID
+	}
+	return ids
+}
+
+// Explain returns ranked results with a per-component score breakdown.
+func (s *lexicalSearcher) Explain(query string, limit int, docs []SearchDoc) ([]Explanation, error) {
+	return s.rank(query, limit, docs, true), nil
+}
+
+// rank scores, sorts, and limits docs for query. Score components are only
+// collected when explain is true.
+func (s *lexicalSearcher) rank(query string, limit int, docs []SearchDoc, explain bool) []Explanation {
 	if limit <= 0 {
-		return []Summary{}, nil
+		return []Explanation{}
+	}
+	query = strings.TrimSpace(normalizeSeparators(query))
+	if !s.caseSensitive {
+		query = strings.ToLower(query)
 	}
-	query = strings.ToLower(strings.TrimSpace(query))
 
-	// Empty query returns all results (up to limit)
+	// Whitespace-only queries trim to empty and are handled identically.
+	// Empty query returns all results (up to limit) unless configured not to.
 	if query == "" {
-		results := make([]Summary, 0, limit)
+		if s.emptyQueryNone {
+			return []Explanation{}
+		}
+		results := make([]Explanation, 0, min(limit, len(docs)))
 		for i, doc := range docs {
 			if i >= limit {
 				break
 			}
-			results = append(results, doc.Summary)
+			results = append(results, Explanation{Summary: doc.Summary})
 		}
-		return results, nil
+		return results
 	}
 
 	// Score and collect matching results
-	var scored []scoredResult
+	var scored []Explanation
 	for _, doc := range docs {
-		score := 0
+		if e, ok := s.score(query, doc, explain); ok {
+			scored = append(scored, e)
+		}
+	}
+
+	// Sort pinned tools first, then by score descending, then ID ascending
+	// for deterministic pagination.
+	sort.Slice(scored, func(i, j int) bool {
+		if pi, pj := s.isPinned(scored[i].Summary.ID), s.isPinned(scored[j].Summary.ID); pi != pj {
+			return pi
+		}
+		if scored[i].Score == scored[j].Score {
+			return scored[i].Summary.ID < scored[j].Summary.ID
+		}
+		return scored[i].Score > scored[j].Score
+	})
+
+	// Apply limit
+	if len(scored) > limit {
+		scored = scored[:limit]
+	}
+	return scored
+}
+
+// score computes the relevance of doc for a lowercased, trimmed query.
+// It reports false when the doc does not match.
+func (s *lexicalSearcher) score(query string, doc SearchDoc, explain bool) (Explanation, bool) {
+	e := Explanation{Summary: doc.Summary}
+	add := func(reason string, points int) {
+		e.Score += points
+		if explain {
+			e.Components = append(e.Components, ScoreComponent{Reason: reason, Points: points})
+		}
+	}
+
+	if s.scoreFunc != nil {
+		if points := s.scoreFunc(query, doc); points > 0 {
+			add("custom score", points)
+		}
+	} else {
+		name := normalizeSeparators(doc.Summary.Name)
+		namespace := normalizeSeparators(doc.Summary.Namespace)
+		if !s.caseSensitive {
+			name, namespace = strings.ToLower(name), strings.ToLower(namespace)
+		}
 
 		// Name match (highest priority)
-		nameLower := strings.ToLower(doc.Summary.Name)
-		if strings.Contains(nameLower, query) {
-			score += 100
-			if nameLower == query {
-				score += 50 // Exact match bonus
+		if strings.Contains(name, query) {
+			add("name match", 100)
+			if name == query {
+				add("exact name match", 50)
+			} else if strings.HasPrefix(name, query) {
+				add("name prefix match", prefixBonus)
 			}
 		}
 
 		// Namespace match
-		nsLower := strings.ToLower(doc.Summary.Namespace)
-		if strings.Contains(nsLower, query) {
-			score += 50
+		if strings.Contains(namespace, query) {
+			add("namespace match", 50)
 		}
 
-		// Description/tags match (via DocText)
-		if score == 0 && strings.Contains(doc.DocText, query) {
-			score += 10
+		// Qualified match: namespace and name together, in either order,
+		// as in "math calc", "math.calc", or "calc math".
+		if namespace != "" && isQualifiedQuery(query, namespace, name) {
+			add("qualified name match", 150)
 		}
 
-		if score > 0 {
-			scored = append(scored, scoredResult{summary: doc.Summary, score: score})
+		// Tag match, which adds to name and namespace matches so tools hit
+		// in several fields outrank single-field hits.
+		if !s.caseSensitive && anyTag(doc.Summary.Tags, query, strings.Contains) {
+			add("tag match", 10)
+			if anyTag(doc.Summary.Tags, query, strings.HasPrefix) {
+				add("tag prefix match", prefixBonus)
+			}
 		}
-	}
 
-	// Sort by score descending, then ID ascending for deterministic pagination.
-	sort.Slice(scored, func(i, j int) bool {
-		if scored[i].score == scored[j].score {
-			return scored[i].summary.ID < scored[j].summary.ID
+		// Description and other indexed text (via DocText), only when no
+		// field above matched: DocText repeats the name and namespace.
+		if e.Score == 0 && !s.caseSensitive && strings.Contains(doc.DocText, query) {
+			add("description match", 10)
 		}
-		return scored[i].score > scored[j].score
-	})
+	}
 
-	// Apply limit
-	if len(scored) > limit {
-		scored = scored[:limit]
+	if e.Score <= 0 {
+		return Explanation{}, false
+	}
+
+	if bonus := int(math.Round(s.popularityWeight * doc.Popularity)); bonus != 0 {
+		add("popularity bonus", bonus)
+	}
+	if s.nameLengthPenalty > 0 {
+		penalty := int(math.Round(s.nameLengthPenalty * float64(utf8.RuneCountInString(doc.Summary.Name))))
+		if penalty = min(penalty, e.Score-1); penalty > 0 {
+			add("name length penalty", -penalty)
+		}
 	}
 
-	// Extract summaries
-	results := make([]Summary, len(scored))
-	for i, sr := range scored {
-		results[i] = sr.summary
+	// Deprecated tools stay discoverable but rank below current tools.
+	if doc.Summary.Deprecated {
+		add("deprecated penalty", -min(deprecatedPenalty, e.Score-1))
+	}
+	// Pinning is a sort key in rank, not points, so no score can overtake
+	// it; the zero-point component records it in explanations.
+	if s.isPinned(doc.ID) {
+		add("pinned", 0)
 	}
+	return e, true
+}
 
-	return results, nil
+// isPinned reports whether id is listed in IndexOptions.PinnedTools.
+func (s *lexicalSearcher) isPinned(id string) bool {
+	_, ok := s.pinned[id]
+	return ok
 }
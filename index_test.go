@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -164,6 +166,31 @@ func TestRegisterTool_InvalidTool(t *testing.T) {
 	}
 }
 
+func TestRegisterTool_NamespaceWithSeparatorRejected(t *testing.T) {
+	idx := NewInMemoryIndex()
+
+	tool := makeTestTool("mytool", "a:b", "A test tool", nil)
+	err := idx.RegisterTool(tool, makeMCPBackend("server1"))
+	if !errors.Is(err, ErrInvalidTool) {
+		t.Fatalf("expected ErrInvalidTool, got %v", err)
+	}
+	if _, _, err := idx.GetTool("a:b:mytool"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected tool not to be registered, got %v", err)
+	}
+
+	// The other half of an "a:b:mytool" collision is rejected too, and no
+	// registration path skips the check.
+	if err := idx.RegisterTool(makeTestTool("b:mytool", "a", "A test tool", nil), makeMCPBackend("server1")); !errors.Is(err, ErrInvalidTool) {
+		t.Errorf("expected ErrInvalidTool for separator in name, got %v", err)
+	}
+	if err := idx.RegisterTools([]ToolRegistration{{Tool: tool, Backend: makeMCPBackend("server1")}}); !errors.Is(err, ErrInvalidTool) {
+		t.Errorf("expected ErrInvalidTool from RegisterTools, got %v", err)
+	}
+	if _, err := idx.ReplaceAll([]ToolRegistration{{Tool: tool, Backend: makeMCPBackend("server1")}}); !errors.Is(err, ErrInvalidTool) {
+		t.Errorf("expected ErrInvalidTool from ReplaceAll, got %v", err)
+	}
+}
+
 func TestRegisterTool_InvalidBackend(t *testing.T) {
 	idx := NewInMemoryIndex()
 
@@ -277,6 +304,162 @@ func TestRegisterToolsFromMCP(t *testing.T) {
 	}
 }
 
+func TestRegisterTools_BatchConflictRejectedBeforeMutation(t *testing.T) {
+	idx := NewInMemoryIndex()
+
+	conflicting := makeTestTool("tool1", "ns", "Different description", nil)
+	regs := []ToolRegistration{
+		{Tool: makeTestTool("tool0", "ns", "Tool 0", nil), Backend: makeMCPBackend("server1")},
+		{Tool: makeTestTool("tool1", "ns", "Tool 1", nil), Backend: makeMCPBackend("server1")},
+		{Tool: conflicting, Backend: makeMCPBackend("server2")},
+	}
+	if err := idx.RegisterTools(regs); !errors.Is(err, ErrInvalidTool) || !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict wrapping ErrInvalidTool, got %v", err)
+	}
+	if _, _, err := idx.GetTool("ns:tool0"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected no partial registration, got %v", err)
+	}
+}
+
+func TestRegisterTools_BatchDuplicatesMergeBackends(t *testing.T) {
+	idx := NewInMemoryIndex()
+
+	tool := makeTestTool("tool1", "ns", "Tool 1", nil)
+	regs := []ToolRegistration{
+		{Tool: tool, Backend: makeMCPBackend("server1")},
+		{Tool: tool, Backend: makeLocalBackend("handler")},
+	}
+	if err := idx.RegisterTools(regs); err != nil {
+		t.Fatalf("RegisterTools failed: %v", err)
+	}
+	backends, err := idx.GetAllBackends("ns:tool1")
+	if err != nil {
+		t.Fatalf("GetAllBackends failed: %v", err)
+	}
+	if len(backends) != 2 {
+		t.Fatalf("expected 2 merged backends, got %d", len(backends))
+	}
+}
+
+func TestRegisterToolsPartial_ClassifiesFailures(t *testing.T) {
+	idx := NewInMemoryIndex(IndexOptions{StrictBackendReplace: true})
+	mismatched := makeMCPBackend("server1")
+	mismatched.Local = &toolmodel.LocalBackend{Name: "stray"}
+
+	invalidTool := makeTestTool("bad name", "ns", "Invalid", nil)
+	regs := []ToolRegistration{
+		{Tool: makeTestTool("ok", "ns", "OK", nil), Backend: makeMCPBackend("server1")},
+		{Tool: invalidTool, Backend: makeMCPBackend("server1")},
+		{Tool: makeTestTool("nobackend", "ns", "No backend", nil), Backend: toolmodel.ToolBackend{Kind: toolmodel.BackendKindMCP}},
+		{Tool: makeTestTool("ok", "ns", "Different description", nil), Backend: makeMCPBackend("server2")},
+		{Tool: makeTestTool("also_ok", "ns", "OK", nil), Backend: makeMCPBackend("server1")},
+		{Tool: makeTestTool("ok", "ns", "OK", nil), Backend: mismatched},
+	}
+	failures := idx.RegisterToolsPartial(regs)
+
+	want := []struct {
+		index    int
+		toolID   string
+		sentinel error
+	}{
+		{1, "ns:bad name", ErrInvalidTool},
+		{2, "ns:nobackend", ErrInvalidBackend},
+		{3, "ns:ok", ErrInvalidTool},
+		{5, "ns:ok", ErrInvalidBackend},
+	}
+	if len(failures) != len(want) {
+		t.Fatalf("expected %d failures, got %+v", len(want), failures)
+	}
+	for i, w := range want {
+		f := failures[i]
+		if f.Index != w.index || f.ToolID != w.toolID || f.Sentinel != w.sentinel || !errors.Is(f.Err, w.sentinel) {
+			t.Errorf("failure %d: expected index %d, tool %q, sentinel %v; got %+v", i, w.index, w.toolID, w.sentinel, f)
+		}
+	}
+	if !errors.Is(failures[2].Err, ErrConflict) {
+		t.Errorf("expected conflicting entry to wrap ErrConflict, got %v", failures[2].Err)
+	}
+	if !errors.Is(failures[3].Err, ErrBackendConflict) {
+		t.Errorf("expected strict replacement to wrap ErrBackendConflict, got %v", failures[3].Err)
+	}
+
+	for _, id := range []string{"ns:ok", "ns:also_ok"} {
+		if _, _, err := idx.GetTool(id); err != nil {
+			t.Errorf("expected %s to be registered, got %v", id, err)
+		}
+	}
+	if failures := idx.RegisterToolsPartial(regs[:1]); failures != nil {
+		t.Errorf("expected nil failures for a clean batch, got %+v", failures)
+	}
+}
+
+func TestRegisterTool_RejectSelfReferentialBackends(t *testing.T) {
+	tool := makeTestTool("lookup", "crm", "Look up a contact", nil)
+	self := makeProviderBackend("crm", "lookup")
+
+	// Allowed by default.
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, tool, self)
+
+	idx = NewInMemoryIndex(IndexOptions{RejectSelfReferentialBackends: true})
+	if err := idx.RegisterTool(tool, self); !errors.Is(err, ErrInvalidBackend) {
+		t.Fatalf("expected ErrInvalidBackend, got %v", err)
+	}
+	if _, _, err := idx.GetTool("crm:lookup"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("rejected registration must not be stored, got %v", err)
+	}
+	mustRegister(t, idx, tool, makeProviderBackend("crm", "contacts.lookup"))
+}
+
+func TestUpsertTool(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("sync", "files", "Old description", []string{"old"}), makeMCPBackend("a"))
+	mustRegister(t, idx, makeTestTool("sync", "files", "Old description", []string{"old"}), makeMCPBackend("b"))
+
+	var events []ChangeEvent
+	idx.OnChange(func(e ChangeEvent) { events = append(events, e) })
+
+	// Different MCP fields would be rejected by RegisterTool.
+	updated := makeTestTool("sync", "files", "New description", []string{"new"})
+	if err := idx.RegisterTool(updated, makeMCPBackend("c")); !errors.Is(err, ErrInvalidTool) {
+		t.Fatalf("expected RegisterTool to reject changed MCP fields, got %v", err)
+	}
+	if err := idx.UpsertTool(updated, []toolmodel.ToolBackend{makeMCPBackend("c"), makeLocalBackend("sync")}); err != nil {
+		t.Fatalf("UpsertTool failed: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != ChangeUpdated || events[0].ToolID != "files:sync" {
+		t.Fatalf("expected a single update event, got %+v", events)
+	}
+
+	tool, _, backends, err := idx.ResolveTool("files:sync")
+	if err != nil {
+		t.Fatalf("ResolveTool failed: %v", err)
+	}
+	if tool.Description != "New description" || len(backends) != 2 {
+		t.Fatalf("expected whole record replaced, got %+v with %+v", tool, backends)
+	}
+	if results, _ := idx.Search("old", 10); len(results) != 0 {
+		t.Errorf("expected old tags gone, got %+v", results)
+	}
+
+	if err := idx.UpsertTool(makeTestTool("new", "files", "Brand new", nil), []toolmodel.ToolBackend{makeLocalBackend("n")}); err != nil {
+		t.Fatalf("UpsertTool for a new tool failed: %v", err)
+	}
+	if len(events) != 2 || events[1].Type != ChangeRegistered {
+		t.Fatalf("expected a registered event for a new tool, got %+v", events)
+	}
+
+	if err := idx.UpsertTool(updated, nil); !errors.Is(err, ErrInvalidBackend) {
+		t.Errorf("expected ErrInvalidBackend without backends, got %v", err)
+	}
+	if err := idx.UpsertTool(updated, []toolmodel.ToolBackend{{Kind: toolmodel.BackendKindMCP}}); !errors.Is(err, ErrInvalidBackend) {
+		t.Errorf("expected ErrInvalidBackend for invalid backend, got %v", err)
+	}
+	if backends, _ := idx.GetAllBackends("files:sync"); len(backends) != 2 {
+		t.Errorf("failed upsert must not modify the record, got %+v", backends)
+	}
+}
+
 // ============================================================
 // Tests for Backend Identity and Replacement
 // ============================================================
@@ -398,6 +581,15 @@ func TestRegisterTool_MCPFieldMismatchRejected(t *testing.T) {
 	if !errors.Is(err, ErrInvalidTool) {
 		t.Errorf("expected ErrInvalidTool, got %v", err)
 	}
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+
+	// Malformed tools are invalid but not conflicts.
+	err = idx.RegisterTool(makeTestTool("", "ns", "desc", nil), makeMCPBackend("server1"))
+	if !errors.Is(err, ErrInvalidTool) || errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrInvalidTool without ErrConflict, got %v", err)
+	}
 }
 
 func TestRegisterTool_MCPFieldMismatchSchema(t *testing.T) {
@@ -798,6 +990,64 @@ func TestUnregisterBackend_ProviderEmptyParts(t *testing.T) {
 	}
 }
 
+func TestUnregisterBackend_ProviderIDSeparator(t *testing.T) {
+	idx := NewInMemoryIndex(IndexOptions{ProviderIDSeparator: "|"})
+	tool := makeTestTool("mytool", "ns", "A tool", nil)
+	mustRegister(t, idx, tool, makeProviderBackend("urn:acme:p1", "tool-a"))
+	mustRegister(t, idx, tool, makeLocalBackend("keep"))
+
+	if ids, err := idx.ToolsUsingBackend(toolmodel.BackendKindProvider, "urn:acme:p1|tool-a"); err != nil || len(ids) != 1 {
+		t.Fatalf("expected provider lookup with custom separator, got %v, %v", ids, err)
+	}
+	if err := idx.UnregisterBackend("ns:mytool", toolmodel.BackendKindProvider, "urn:acme:p1|tool-a"); err != nil {
+		t.Fatalf("UnregisterBackend with custom separator failed: %v", err)
+	}
+	if err := idx.UnregisterBackend("ns:mytool", toolmodel.BackendKindProvider, "urn:acme:p1"); !errors.Is(err, ErrInvalidBackend) {
+		t.Errorf("expected ErrInvalidBackend without the custom separator, got %v", err)
+	}
+
+	for _, sep := range []string{"::", "ab"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected NewInMemoryIndex to panic for separator %q", sep)
+				}
+			}()
+			NewInMemoryIndex(IndexOptions{ProviderIDSeparator: sep})
+		}()
+	}
+}
+
+func TestRegisterTool_StrictBackendReplace(t *testing.T) {
+	tool := makeTestTool("sync", "files", "Sync files", nil)
+	backend := makeMCPBackend("files")
+	// Same MCP identity, but carrying stray details from another kind.
+	mismatched := makeMCPBackend("files")
+	mismatched.Local = &toolmodel.LocalBackend{Name: "sync"}
+
+	// Default: replaced silently.
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, tool, backend)
+	mustRegister(t, idx, tool, mismatched)
+	if backends, _ := idx.GetAllBackends("files:sync"); len(backends) != 1 || backends[0].Local == nil {
+		t.Fatalf("expected silent replacement, got %+v", backends)
+	}
+
+	idx = NewInMemoryIndex(IndexOptions{StrictBackendReplace: true})
+	mustRegister(t, idx, tool, backend)
+	mustRegister(t, idx, tool, makeMCPBackend("files")) // identical is fine
+	retagged := makeTestTool("sync", "files", "Sync files", []string{"retagged"})
+	if err := idx.RegisterTool(retagged, mismatched); !errors.Is(err, ErrBackendConflict) {
+		t.Fatalf("expected ErrBackendConflict, got %v", err)
+	}
+	if results, _ := idx.Search("retagged", 10); len(results) != 0 {
+		t.Fatalf("rejected registration must not update tags, got %+v", results)
+	}
+	if backends, _ := idx.GetAllBackends("files:sync"); backends[0].Local != nil {
+		t.Fatalf("conflicting backend must not replace the original, got %+v", backends)
+	}
+}
+
 // ============================================================
 // Tests for Backend Selection Policy
 // ============================================================
@@ -881,6 +1131,27 @@ func TestGetTool_NotFound(t *testing.T) {
 	}
 }
 
+func TestGetToolByParts(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("calc", "math", "Calculator", nil), makeLocalBackend("calc"))
+	mustRegister(t, idx, makeTestTool("plain", "", "No namespace", nil), makeLocalBackend("plain"))
+
+	tool, _, err := idx.GetToolByParts("math", "calc")
+	if err != nil {
+		t.Fatalf("GetToolByParts failed: %v", err)
+	}
+	if tool.Name != "calc" || tool.Namespace != "math" {
+		t.Errorf("unexpected tool: %s/%s", tool.Namespace, tool.Name)
+	}
+
+	if _, _, err := idx.GetToolByParts("", "plain"); err != nil {
+		t.Errorf("GetToolByParts without namespace failed: %v", err)
+	}
+	if _, _, err := idx.GetToolByParts("math", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
 func TestGetAllBackends_NotFound(t *testing.T) {
 	idx := NewInMemoryIndex()
 
@@ -890,6 +1161,346 @@ func TestGetAllBackends_NotFound(t *testing.T) {
 	}
 }
 
+func TestGetBackendsPage(t *testing.T) {
+	idx := NewInMemoryIndex()
+	tool := makeTestTool("calc", "math", "Calculator", nil)
+	mustRegister(t, idx, tool, makeProviderBackend("p2", "calc"))
+	mustRegister(t, idx, tool, makeProviderBackend("p1", "calc"))
+	mustRegister(t, idx, tool, makeProviderBackend("p3", "calc"))
+
+	page, cursor, err := idx.GetBackendsPage("math:calc", 2, "")
+	if err != nil {
+		t.Fatalf("GetBackendsPage failed: %v", err)
+	}
+	if len(page) != 2 || cursor == "" {
+		t.Fatalf("unexpected first page: %d backends, cursor %q", len(page), cursor)
+	}
+	if page[0].Provider.ProviderID != "p1" || page[1].Provider.ProviderID != "p2" {
+		t.Fatalf("expected identity order, got %s, %s", page[0].Provider.ProviderID, page[1].Provider.ProviderID)
+	}
+
+	// Unrelated mutations do not invalidate the cursor.
+	mustRegister(t, idx, makeTestTool("other", "math", "Other", nil), makeLocalBackend("other"))
+	page, next, err := idx.GetBackendsPage("math:calc", 2, cursor)
+	if err != nil {
+		t.Fatalf("GetBackendsPage with cursor failed: %v", err)
+	}
+	if len(page) != 1 || next != "" || page[0].Provider.ProviderID != "p3" {
+		t.Fatalf("unexpected second page: %+v, %q", page, next)
+	}
+
+	// Changing this tool's backend set does.
+	mustRegister(t, idx, tool, makeLocalBackend("calc"))
+	if _, _, err := idx.GetBackendsPage("math:calc", 2, cursor); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor, got %v", err)
+	}
+
+	if _, _, err := idx.GetBackendsPage("math:missing", 2, ""); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestResolveTool(t *testing.T) {
+	idx := NewInMemoryIndex()
+	tool := makeTestTool("run", "ops", "Run a job", nil)
+	mustRegister(t, idx, tool, makeMCPBackend("ops"))
+	mustRegister(t, idx, tool, makeLocalBackend("run"))
+
+	got, backend, backends, err := idx.ResolveTool("ops:run")
+	if err != nil {
+		t.Fatalf("ResolveTool failed: %v", err)
+	}
+	if got.Name != "run" {
+		t.Errorf("unexpected tool: %+v", got)
+	}
+	if backend.Kind != toolmodel.BackendKindLocal {
+		t.Errorf("expected default local backend, got %v", backend.Kind)
+	}
+	if len(backends) != 2 {
+		t.Fatalf("expected 2 backends, got %d", len(backends))
+	}
+
+	// The returned slice is a copy.
+	backends[0] = toolmodel.ToolBackend{}
+	if all, _ := idx.GetAllBackends("ops:run"); all[0].Kind == "" {
+		t.Error("ResolveTool must return a copy of the backends")
+	}
+
+	if _, _, _, err := idx.ResolveTool("ops:missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestListAllBackends(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("b", "ns", "B", nil), makeMCPBackend("s1"))
+	mustRegister(t, idx, makeTestTool("a", "ns", "A", nil), makeMCPBackend("s2"))
+	mustRegister(t, idx, makeTestTool("a", "ns", "A", nil), makeLocalBackend("a"))
+
+	var all []ToolBackendRef
+	cursor := ""
+	for {
+		page, next, err := idx.ListAllBackends(2, cursor)
+		if err != nil {
+			t.Fatalf("ListAllBackends failed: %v", err)
+		}
+		all = append(all, page...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	if len(all) != 3 {
+		t.Fatalf("expected 3 backend refs, got %+v", all)
+	}
+	if all[0].ToolID != "ns:a" || all[1].ToolID != "ns:a" || all[2].ToolID != "ns:b" {
+		t.Fatalf("expected refs sorted by tool ID, got %+v", all)
+	}
+	if backendIdentity(all[0].Backend) > backendIdentity(all[1].Backend) {
+		t.Errorf("expected backends sorted by identity within a tool, got %+v", all[:2])
+	}
+
+	_, next, _ := idx.ListAllBackends(1, "")
+	mustRegister(t, idx, makeTestTool("c", "ns", "C", nil), makeLocalBackend("c"))
+	if _, _, err := idx.ListAllBackends(1, next); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor after mutation, got %v", err)
+	}
+}
+
+func TestGetSummary(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("get_repo", "github", strings.Repeat("x", MaxShortDescriptionLen+10), []string{"Repo"}), makeMCPBackend("gh"))
+
+	summary, err := idx.GetSummary("github:get_repo")
+	if err != nil {
+		t.Fatalf("GetSummary failed: %v", err)
+	}
+	results, _ := idx.Search("get_repo", 1)
+	if !reflect.DeepEqual(summary, results[0]) {
+		t.Fatalf("expected GetSummary to match the search summary, got %+v vs %+v", summary, results[0])
+	}
+	if len(summary.ShortDescription) != MaxShortDescriptionLen {
+		t.Errorf("expected truncated short description, got %d chars", len(summary.ShortDescription))
+	}
+
+	summary.Tags[0] = "mutated"
+	again, _ := idx.GetSummary("github:get_repo")
+	if again.Tags[0] != "repo" {
+		t.Errorf("expected cached tags to be unaffected by caller mutation, got %v", again.Tags)
+	}
+
+	if _, err := idx.GetSummary("github:missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := idx.GetSummary(""); !errors.Is(err, ErrEmptyID) {
+		t.Errorf("expected ErrEmptyID, got %v", err)
+	}
+}
+
+func TestHasBackend(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("t", "ns", "T", nil), makeMCPBackend("s1"))
+	mustRegister(t, idx, makeTestTool("t", "ns", "T", nil), makeProviderBackend("p", "x"))
+
+	tests := []struct {
+		kind      toolmodel.BackendKind
+		backendID string
+		want      bool
+	}{
+		{toolmodel.BackendKindMCP, "s1", true},
+		{toolmodel.BackendKindMCP, "s2", false},
+		{toolmodel.BackendKindProvider, "p:x", true},
+		{toolmodel.BackendKindLocal, "s1", false},
+	}
+	for _, tt := range tests {
+		got, err := idx.HasBackend("ns:t", tt.kind, tt.backendID)
+		if err != nil {
+			t.Fatalf("HasBackend(%s, %q) failed: %v", tt.kind, tt.backendID, err)
+		}
+		if got != tt.want {
+			t.Errorf("HasBackend(%s, %q) = %v, want %v", tt.kind, tt.backendID, got, tt.want)
+		}
+	}
+
+	if _, err := idx.HasBackend("ns:missing", toolmodel.BackendKindMCP, "s1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing tool, got %v", err)
+	}
+	if _, err := idx.HasBackend("ns:t", toolmodel.BackendKindProvider, "p"); !errors.Is(err, ErrInvalidBackend) {
+		t.Errorf("expected ErrInvalidBackend for malformed provider ID, got %v", err)
+	}
+}
+
+func TestToolsUsingBackend(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("b", "ns1", "B", nil), makeMCPBackend("s1"))
+	mustRegister(t, idx, makeTestTool("a", "ns2", "A", nil), makeMCPBackend("s1"))
+	mustRegister(t, idx, makeTestTool("a", "ns2", "A", nil), makeLocalBackend("runner"))
+	mustRegister(t, idx, makeTestTool("c", "ns1", "C", nil), makeProviderBackend("p", "c"))
+	if err := idx.SetHidden("ns1:b", true); err != nil {
+		t.Fatalf("SetHidden failed: %v", err)
+	}
+
+	tests := []struct {
+		kind      toolmodel.BackendKind
+		backendID string
+		want      []string
+	}{
+		{toolmodel.BackendKindMCP, "s1", []string{"ns1:b", "ns2:a"}},
+		{toolmodel.BackendKindLocal, "runner", []string{"ns2:a"}},
+		{toolmodel.BackendKindProvider, "p:c", []string{"ns1:c"}},
+		{toolmodel.BackendKindMCP, "missing", []string{}},
+	}
+	for _, tt := range tests {
+		got, err := idx.ToolsUsingBackend(tt.kind, tt.backendID)
+		if err != nil {
+			t.Fatalf("ToolsUsingBackend(%s, %q) failed: %v", tt.kind, tt.backendID, err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ToolsUsingBackend(%s, %q) = %v, want %v", tt.kind, tt.backendID, got, tt.want)
+		}
+	}
+
+	if _, err := idx.ToolsUsingBackend(toolmodel.BackendKindProvider, "p"); !errors.Is(err, ErrInvalidBackend) {
+		t.Errorf("expected ErrInvalidBackend for malformed provider ID, got %v", err)
+	}
+	if _, err := idx.ToolsUsingBackend("bogus", "x"); !errors.Is(err, ErrInvalidBackend) {
+		t.Errorf("expected ErrInvalidBackend for unknown kind, got %v", err)
+	}
+}
+
+func TestListToolIDs(t *testing.T) {
+	idx := NewInMemoryIndex()
+	if ids := idx.ListToolIDs(); len(ids) != 0 {
+		t.Fatalf("expected no IDs, got %v", ids)
+	}
+	mustRegister(t, idx, makeTestTool("b", "ns", "B", nil), makeLocalBackend("b"))
+	mustRegister(t, idx, makeTestTool("a", "ns", "A", nil), makeLocalBackend("a"))
+	mustRegister(t, idx, makeTestTool("c", "ns", "C", nil), makeLocalBackend("c"))
+	if err := idx.SetHidden("ns:c", true); err != nil {
+		t.Fatalf("SetHidden failed: %v", err)
+	}
+
+	want := []string{"ns:a", "ns:b", "ns:c"}
+	if ids := idx.ListToolIDs(); !slices.Equal(ids, want) {
+		t.Fatalf("expected %v, got %v", want, ids)
+	}
+
+	var paged []string
+	cursor := ""
+	for {
+		page, next, err := idx.ListToolIDsPage(2, cursor)
+		if err != nil {
+			t.Fatalf("ListToolIDsPage failed: %v", err)
+		}
+		paged = append(paged, page...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if !slices.Equal(paged, want) {
+		t.Fatalf("expected paged %v, got %v", want, paged)
+	}
+}
+
+func TestListTools(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("deploy", "ci", "Deploy", []string{"Release", "prod"}), makeMCPBackend("s"))
+	mustRegister(t, idx, makeTestTool("lint", "ci", "Lint", []string{"quality"}), makeLocalBackend("l"))
+	mustRegister(t, idx, makeTestTool("lint", "ci", "Lint", []string{"quality"}), makeMCPBackend("s"))
+	mustRegister(t, idx, makeTestTool("read", "files", "Read", []string{"release"}), makeLocalBackend("r"))
+	mustRegister(t, idx, makeTestTool("secret", "ci", "Secret", nil), makeLocalBackend("x"))
+	if err := idx.SetHidden("ci:secret", true); err != nil {
+		t.Fatalf("SetHidden failed: %v", err)
+	}
+
+	tests := map[string]struct {
+		filter ListFilter
+		want   []string
+	}{
+		"all visible": {ListFilter{}, []string{"ci:deploy", "ci:lint", "files:read"}},
+		"with hidden": {ListFilter{IncludeHidden: true}, []string{"ci:deploy", "ci:lint", "ci:secret", "files:read"}},
+		"namespace":   {ListFilter{Namespace: "files"}, []string{"files:read"}},
+		"tags":        {ListFilter{Tags: []string{" RELEASE "}}, []string{"ci:deploy", "files:read"}},
+		"all tags":    {ListFilter{Tags: []string{"release", "prod"}}, []string{"ci:deploy"}},
+		"any backend": {ListFilter{BackendKind: toolmodel.BackendKindLocal}, []string{"ci:lint", "files:read"}},
+		"combined":    {ListFilter{Namespace: "ci", BackendKind: toolmodel.BackendKindMCP}, []string{"ci:deploy", "ci:lint"}},
+		"unmatchable": {ListFilter{Tags: []string{"  "}}, []string{}},
+		"no matches":  {ListFilter{Namespace: "none"}, []string{}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			summaries, err := idx.ListTools(tt.filter)
+			if err != nil {
+				t.Fatalf("ListTools failed: %v", err)
+			}
+			if got := summaryIDs(summaries); !slices.Equal(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := idx.ListTools(ListFilter{BackendKind: "ftp"}); !errors.Is(err, ErrInvalidBackend) {
+		t.Errorf("expected ErrInvalidBackend for unknown kind, got %v", err)
+	}
+}
+
+func TestListToolsPage(t *testing.T) {
+	idx := NewInMemoryIndex()
+	for _, name := range []string{"e", "c", "a", "d", "b"} {
+		mustRegister(t, idx, makeTestTool(name, "ns", "Tool", nil), makeLocalBackend(name))
+	}
+	mustRegister(t, idx, makeTestTool("z", "other", "Tool", nil), makeLocalBackend("z"))
+
+	var paged []string
+	cursor := ""
+	for {
+		page, next, err := idx.ListToolsPage(ListFilter{Namespace: "ns"}, 2, cursor)
+		if err != nil {
+			t.Fatalf("ListToolsPage failed: %v", err)
+		}
+		paged = append(paged, summaryIDs(page)...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if want := []string{"ns:a", "ns:b", "ns:c", "ns:d", "ns:e"}; !slices.Equal(paged, want) {
+		t.Fatalf("expected %v, got %v", want, paged)
+	}
+
+	_, cursor, _ = idx.ListToolsPage(ListFilter{}, 2, "")
+	mustRegister(t, idx, makeTestTool("f", "ns", "Tool", nil), makeLocalBackend("f"))
+	if _, _, err := idx.ListToolsPage(ListFilter{}, 2, cursor); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor after a mutation, got %v", err)
+	}
+	if _, _, err := idx.ListToolsPage(ListFilter{}, 0, ""); err == nil {
+		t.Error("expected error for non-positive limit")
+	}
+}
+
+func TestEmptyIDRejected(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("t", "ns", "T", nil), makeLocalBackend("t"))
+
+	if _, _, err := idx.GetTool(""); !errors.Is(err, ErrEmptyID) || errors.Is(err, ErrNotFound) {
+		t.Errorf("GetTool: expected ErrEmptyID, got %v", err)
+	}
+	if _, err := idx.GetAllBackends(""); !errors.Is(err, ErrEmptyID) {
+		t.Errorf("GetAllBackends: expected ErrEmptyID, got %v", err)
+	}
+	if err := idx.UnregisterBackend("", toolmodel.BackendKindLocal, "t"); !errors.Is(err, ErrEmptyID) {
+		t.Errorf("UnregisterBackend: expected ErrEmptyID, got %v", err)
+	}
+	if _, _, err := idx.GetToolWith("", nil); !errors.Is(err, ErrEmptyID) {
+		t.Errorf("GetToolWith: expected ErrEmptyID, got %v", err)
+	}
+	if _, _, _, err := idx.ResolveTool(""); !errors.Is(err, ErrEmptyID) {
+		t.Errorf("ResolveTool: expected ErrEmptyID, got %v", err)
+	}
+}
+
 // ============================================================
 // Tests for Namespaces
 // ============================================================
@@ -975,37 +1586,115 @@ func TestListNamespaces_Deterministic(t *testing.T) {
 	}
 }
 
-// ============================================================
-// Tests for Search
-// ============================================================
-
-func TestSearch_ByName(t *testing.T) {
+func TestHasNamespace(t *testing.T) {
 	idx := NewInMemoryIndex()
+	if idx.HasNamespace("math") {
+		t.Fatal("expected no namespaces in empty index")
+	}
 
-	mustRegister(t, idx, makeTestTool("calculator", "math", "A calculator tool", nil), makeMCPBackend("s"))
-	mustRegister(t, idx, makeTestTool("weather", "api", "Weather information", nil), makeMCPBackend("s"))
-
-	results, err := idx.Search("calculator", 10)
-	if err != nil {
-		t.Fatalf("Search failed: %v", err)
+	mustRegister(t, idx, makeTestTool("add", "math", "Add", nil), makeLocalBackend("add"))
+	if !idx.HasNamespace("math") {
+		t.Error("expected math namespace to exist")
+	}
+	if idx.HasNamespace("Math") {
+		t.Error("expected exact namespace comparison")
 	}
 
-	if len(results) == 0 {
-		t.Fatal("expected at least one result")
+	if err := idx.UnregisterBackend("math:add", toolmodel.BackendKindLocal, "add"); err != nil {
+		t.Fatalf("UnregisterBackend failed: %v", err)
 	}
-	if results[0].Name != "calculator" {
-		t.Errorf("expected first result to be calculator, got %q", results[0].Name)
+	if idx.HasNamespace("math") {
+		t.Error("expected namespace to disappear with its last tool")
 	}
 }
 
-func TestSearch_ByNamespace(t *testing.T) {
+func TestCounts(t *testing.T) {
 	idx := NewInMemoryIndex()
+	if idx.Count() != 0 || idx.NamespaceCount() != 0 {
+		t.Fatalf("expected empty counts, got %d tools in %d namespaces", idx.Count(), idx.NamespaceCount())
+	}
 
-	mustRegister(t, idx, makeTestTool("add", "math", "Addition", nil), makeMCPBackend("s"))
-	mustRegister(t, idx, makeTestTool("sub", "math", "Subtraction", nil), makeMCPBackend("s"))
-	mustRegister(t, idx, makeTestTool("weather", "api", "Weather", nil), makeMCPBackend("s"))
+	mustRegister(t, idx, makeTestTool("add", "math", "Add", nil), makeLocalBackend("add"))
+	mustRegister(t, idx, makeTestTool("sub", "math", "Subtract", nil), makeLocalBackend("sub"))
+	mustRegister(t, idx, makeTestTool("fetch", "web", "Fetch", nil), makeLocalBackend("fetch"))
+	mustRegister(t, idx, makeTestTool("fetch", "web", "Fetch", nil), makeMCPBackend("web"))
+	if err := idx.SetHidden("web:fetch", true); err != nil {
+		t.Fatalf("SetHidden failed: %v", err)
+	}
 
-	results, err := idx.Search("math", 10)
+	if got := idx.Count(); got != 3 {
+		t.Errorf("Count = %d, want 3", got)
+	}
+	if got := idx.NamespaceCount(); got != 2 {
+		t.Errorf("NamespaceCount = %d, want 2", got)
+	}
+	if got := idx.CountByNamespace("math"); got != 2 {
+		t.Errorf("CountByNamespace(math) = %d, want 2", got)
+	}
+	if got := idx.CountByNamespace("web"); got != 1 {
+		t.Errorf("CountByNamespace(web) = %d, want 1", got)
+	}
+	if got := idx.CountByNamespace("missing"); got != 0 {
+		t.Errorf("CountByNamespace(missing) = %d, want 0", got)
+	}
+}
+
+func TestNamespaceCounts(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("fetch", "web", "Fetch", nil), makeLocalBackend("fetch"))
+	mustRegister(t, idx, makeTestTool("add", "math", "Add", nil), makeLocalBackend("add"))
+	mustRegister(t, idx, makeTestTool("sub", "math", "Subtract", nil), makeLocalBackend("sub"))
+	mustRegister(t, idx, makeTestTool("secret", "ops", "Hidden", nil), makeLocalBackend("secret"))
+	if err := idx.SetHidden("ops:secret", true); err != nil {
+		t.Fatalf("SetHidden failed: %v", err)
+	}
+
+	got := idx.NamespaceCounts()
+	want := []NamespaceCount{{"math", 2}, {"web", 1}}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	mustRegister(t, idx, makeTestTool("post", "web", "Post", nil), makeLocalBackend("post"))
+	mustRegister(t, idx, makeTestTool("put", "web", "Put", nil), makeLocalBackend("put"))
+	got = idx.NamespaceCounts(NamespaceCountOptions{ByCount: true})
+	want = []NamespaceCount{{"web", 3}, {"math", 2}}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v by count, got %v", want, got)
+	}
+}
+
+// ============================================================
+// Tests for Search
+// ============================================================
+
+func TestSearch_ByName(t *testing.T) {
+	idx := NewInMemoryIndex()
+
+	mustRegister(t, idx, makeTestTool("calculator", "math", "A calculator tool", nil), makeMCPBackend("s"))
+	mustRegister(t, idx, makeTestTool("weather", "api", "Weather information", nil), makeMCPBackend("s"))
+
+	results, err := idx.Search("calculator", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if len(results) == 0 {
+		t.Fatal("expected at least one result")
+	}
+	if results[0].Name != "calculator" {
+		t.Errorf("expected first result to be calculator, got %q", results[0].Name)
+	}
+}
+
+func TestSearch_ByNamespace(t *testing.T) {
+	idx := NewInMemoryIndex()
+
+	mustRegister(t, idx, makeTestTool("add", "math", "Addition", nil), makeMCPBackend("s"))
+	mustRegister(t, idx, makeTestTool("sub", "math", "Subtraction", nil), makeMCPBackend("s"))
+	mustRegister(t, idx, makeTestTool("weather", "api", "Weather", nil), makeMCPBackend("s"))
+
+	results, err := idx.Search("math", 10)
 	if err != nil {
 		t.Fatalf("Search failed: %v", err)
 	}
@@ -1142,6 +1831,747 @@ func TestSearch_EmptyQuery(t *testing.T) {
 	}
 }
 
+func TestSearch_WhitespaceQueryMatchesEmptyQuery(t *testing.T) {
+	for _, returnAll := range []bool{true, false} {
+		idx := NewInMemoryIndex(IndexOptions{EmptyQueryReturnsAll: &returnAll})
+		mustRegister(t, idx, makeTestTool("tool1", "ns", "desc", nil), makeMCPBackend("s"))
+		mustRegister(t, idx, makeTestTool("tool2", "ns", "desc", nil), makeMCPBackend("s"))
+
+		want := 0
+		if returnAll {
+			want = 2
+		}
+		for _, query := range []string{"", "   ", "\t\n"} {
+			results, err := idx.Search(query, 10)
+			if err != nil {
+				t.Fatalf("Search(%q) failed: %v", query, err)
+			}
+			if len(results) != want {
+				t.Errorf("EmptyQueryReturnsAll=%v, Search(%q): expected %d results, got %d", returnAll, query, want, len(results))
+			}
+		}
+	}
+}
+
+func TestSearch_PinnedToolsRankFirstWhenMatching(t *testing.T) {
+	idx := NewInMemoryIndex(IndexOptions{PinnedTools: []string{"ns:zeta_search"}})
+
+	mustRegister(t, idx, makeTestTool("search", "ns", "Search things", nil), makeLocalBackend("a"))
+	mustRegister(t, idx, makeTestTool("zeta_search", "ns", "Featured search", nil), makeLocalBackend("b"))
+	mustRegister(t, idx, makeTestTool("other", "misc", "Unrelated", nil), makeLocalBackend("c"))
+
+	results, err := idx.Search("search", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 || results[0].ID != "ns:zeta_search" {
+		t.Fatalf("expected pinned tool first, got %+v", results)
+	}
+
+	results, err = idx.Search("unrelated", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "misc:other" {
+		t.Fatalf("pinned tool must not appear for non-matching query, got %+v", results)
+	}
+}
+
+func TestSearch_PinnedOutranksPopularity(t *testing.T) {
+	idx := NewInMemoryIndex(IndexOptions{
+		PinnedTools:      []string{"ns:zeta_search"},
+		PopularityWeight: 1,
+	})
+	mustRegister(t, idx, makeTestTool("search", "ns", "Search things", []string{"search", "search-all"}), makeLocalBackend("a"))
+	mustRegister(t, idx, makeTestTool("zeta_search", "ns", "Featured search", nil), makeLocalBackend("b"))
+	if err := idx.SetPopularity("ns:search", MaxPopularity); err != nil {
+		t.Fatalf("SetPopularity failed: %v", err)
+	}
+
+	results, err := idx.Search("search", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 || results[0].ID != "ns:zeta_search" {
+		t.Fatalf("expected pinned tool ahead of popular tool, got %+v", results)
+	}
+
+	explanations, err := idx.SearchExplain("search", 10)
+	if err != nil {
+		t.Fatalf("SearchExplain failed: %v", err)
+	}
+	if explanations[0].Summary.ID != "ns:zeta_search" || explanations[0].Score >= explanations[1].Score {
+		t.Fatalf("expected pin to order results despite a lower score, got %+v", explanations)
+	}
+}
+
+func TestSearch_ScoreFunc(t *testing.T) {
+	idx := NewInMemoryIndex(IndexOptions{
+		// Rank by description length; exclude tools whose tokens lack the query.
+		ScoreFunc: func(query string, doc SearchDoc) int {
+			if !slices.Contains(doc.Tokens, query) {
+				return 0
+			}
+			return len(doc.Summary.ShortDescription)
+		},
+	})
+	mustRegister(t, idx, makeTestTool("short", "ns", "Copy file", nil), makeLocalBackend("a"))
+	mustRegister(t, idx, makeTestTool("long", "ns", "Copy a file somewhere else", nil), makeLocalBackend("b"))
+	mustRegister(t, idx, makeTestTool("copycat", "ns", "Unrelated", nil), makeLocalBackend("c"))
+
+	results, err := idx.Search("Copy", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 || results[0].ID != "ns:long" || results[1].ID != "ns:short" {
+		t.Fatalf("expected custom scoring order, got %+v", results)
+	}
+}
+
+func TestSearch_DocTextAugmenter(t *testing.T) {
+	idx := NewInMemoryIndex(IndexOptions{
+		DocTextAugmenter: func(tool toolmodel.Tool, base string) string {
+			if tool.Namespace == "finance" {
+				return base + " money ledger"
+			}
+			return base
+		},
+	})
+	mustRegister(t, idx, makeTestTool("post", "finance", "Post an entry", nil), makeLocalBackend("post"))
+	mustRegister(t, idx, makeTestTool("post", "blog", "Post an article", nil), makeLocalBackend("blog"))
+
+	results, err := idx.Search("ledger", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "finance:post" {
+		t.Fatalf("expected augmented match, got %+v", results)
+	}
+	if len(results[0].Tags) != 0 || results[0].ShortDescription != "Post an entry" {
+		t.Errorf("augmentation must not alter summary: %+v", results[0])
+	}
+
+	// Re-registration re-derives augmented text.
+	mustRegister(t, idx, makeTestTool("post", "finance", "Post an entry", []string{"accounting"}), makeLocalBackend("post"))
+	results, _ = idx.Search("ledger", 10)
+	if len(results) != 1 {
+		t.Fatalf("expected augmented text after re-registration, got %+v", results)
+	}
+}
+
+func TestSearch_IndexIconText(t *testing.T) {
+	register := func(idx *InMemoryIndex) {
+		tool := makeTestTool("query", "db", "Run a query", nil)
+		tool.Icons = []mcp.Icon{
+			{Source: "https://cdn.example/icons/postgres-logo.svg?v=2"},
+			{Source: "data:image/png;base64,aGVsbG8="},
+		}
+		mustRegister(t, idx, tool, makeLocalBackend("q"))
+	}
+
+	idx := NewInMemoryIndex()
+	register(idx)
+	if results, _ := idx.Search("postgres", 10); len(results) != 0 {
+		t.Fatalf("expected icon text to be ignored by default, got %+v", results)
+	}
+
+	idx = NewInMemoryIndex(IndexOptions{IndexIconText: true})
+	register(idx)
+	if results, _ := idx.Search("postgres logo", 10); len(results) != 1 || results[0].ID != "db:query" {
+		t.Fatalf("expected match on icon file name, got %+v", results)
+	}
+	if results, _ := idx.Search("base64", 10); len(results) != 0 {
+		t.Fatalf("expected data URIs to contribute nothing, got %+v", results)
+	}
+}
+
+func TestSearchExplain_Breakdown(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("calc", "math", "Calculator", nil), makeLocalBackend("calc"))
+	mustRegister(t, idx, makeTestTool("add", "calc", "Adds numbers", nil), makeLocalBackend("add"))
+
+	explanations, err := idx.SearchExplain("calc", 10)
+	if err != nil {
+		t.Fatalf("SearchExplain failed: %v", err)
+	}
+	if len(explanations) != 2 {
+		t.Fatalf("expected 2 explanations, got %d", len(explanations))
+	}
+
+	top := explanations[0]
+	if top.Summary.ID != "math:calc" || top.Score != 150 {
+		t.Fatalf("unexpected top explanation: %+v", top)
+	}
+	want := []ScoreComponent{{Reason: "name match", Points: 100}, {Reason: "exact name match", Points: 50}}
+	if fmt.Sprint(top.Components) != fmt.Sprint(want) {
+		t.Fatalf("components = %+v, want %+v", top.Components, want)
+	}
+
+	total := 0
+	for _, c := range explanations[1].Components {
+		total += c.Points
+	}
+	if total != explanations[1].Score {
+		t.Fatalf("components sum %d != score %d", total, explanations[1].Score)
+	}
+
+	// Explanation ordering matches Search ordering.
+	results, _ := idx.Search("calc", 10)
+	for i := range results {
+		if results[i].ID != explanations[i].Summary.ID {
+			t.Fatalf("ordering mismatch at %d: %q vs %q", i, results[i].ID, explanations[i].Summary.ID)
+		}
+	}
+}
+
+func TestSearchExplain_MatchesSearchVisibility(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("fetch", "web", "Fetch a page", nil), makeLocalBackend("fetch"))
+	mustRegister(t, idx, makeTestTool("plumb", "internal", "Internal plumbing fetch", nil), makeLocalBackend("plumb"))
+	if err := idx.SetHidden("internal:plumb", true); err != nil {
+		t.Fatalf("SetHidden failed: %v", err)
+	}
+
+	explanations, err := idx.SearchExplain("fetch", 10)
+	if err != nil {
+		t.Fatalf("SearchExplain failed: %v", err)
+	}
+	if len(explanations) != 1 || explanations[0].Summary.ID != "web:fetch" {
+		t.Fatalf("expected hidden tool excluded from explanations, got %+v", explanations)
+	}
+
+	// Searchers without Explain degrade through the fallback like Search.
+	idx = NewInMemoryIndex(IndexOptions{
+		Searcher: &mockSearcher{
+			searchFunc: func(_ string, _ int, _ []SearchDoc) ([]Summary, error) {
+				return nil, errors.New("vector store unavailable")
+			},
+		},
+		SearcherFallback: &lexicalSearcher{},
+	})
+	mustRegister(t, idx, makeTestTool("fetch", "web", "Fetch a page", nil), makeLocalBackend("fetch"))
+	explanations, err = idx.SearchExplain("fetch", 10)
+	if err != nil {
+		t.Fatalf("SearchExplain should degrade to fallback, got %v", err)
+	}
+	if len(explanations) != 1 || explanations[0].Summary.ID != "web:fetch" {
+		t.Fatalf("unexpected fallback explanations: %+v", explanations)
+	}
+}
+
+func TestSearch_PreserveFieldBoundaries(t *testing.T) {
+	tool := makeTestTool("fetch", "web", "page contents", nil)
+
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, tool, makeLocalBackend("f"))
+	if results, _ := idx.Search("web page", 10); len(results) != 1 {
+		t.Fatalf("expected cross-field match by default, got %+v", results)
+	}
+
+	idx = NewInMemoryIndex(IndexOptions{PreserveFieldBoundaries: true})
+	mustRegister(t, idx, tool, makeLocalBackend("f"))
+	if results, _ := idx.Search("web page", 10); len(results) != 0 {
+		t.Fatalf("expected no cross-field match, got %+v", results)
+	}
+	if results, _ := idx.Search("page contents", 10); len(results) != 1 {
+		t.Fatalf("expected within-field phrase match, got %+v", results)
+	}
+}
+
+func TestSearchFiltered_CaseSensitive(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("Query", "db", "Run a query", nil), makeLocalBackend("upper"))
+	mustRegister(t, idx, makeTestTool("query", "db", "Run a query", nil), makeLocalBackend("lower"))
+
+	results, err := idx.SearchFiltered("Query", 10, SearchFilter{})
+	if err != nil {
+		t.Fatalf("SearchFiltered failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected case-insensitive default to match both, got %+v", results)
+	}
+
+	exact := SearchFilter{CaseSensitive: true}
+	results, err = idx.SearchFiltered("Query", 10, exact)
+	if err != nil {
+		t.Fatalf("SearchFiltered failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "db:Query" {
+		t.Fatalf("expected only db:Query, got %+v", results)
+	}
+
+	page, _, err := idx.SearchPageFiltered("query", 10, "", exact)
+	if err != nil {
+		t.Fatalf("SearchPageFiltered failed: %v", err)
+	}
+	if len(page) != 1 || page[0].ID != "db:query" {
+		t.Fatalf("expected only db:query, got %+v", page)
+	}
+
+	// The option is per-search and does not leak into later searches.
+	if results, _ := idx.Search("QUERY", 10); len(results) != 2 {
+		t.Fatalf("expected default search unaffected, got %+v", results)
+	}
+}
+
+func TestSearchFiltered_ExcludeDeprecated(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("fetch", "web", "Fetch a page", nil), makeMCPBackend("s"))
+	mustRegister(t, idx, makeTestTool("fetch2", "web", "Fetch a page", nil), makeMCPBackend("s"))
+	if err := idx.Deprecate("web:fetch", "web:fetch2", ""); err != nil {
+		t.Fatalf("Deprecate failed: %v", err)
+	}
+
+	all, err := idx.SearchFiltered("fetch", 10, SearchFilter{})
+	if err != nil {
+		t.Fatalf("SearchFiltered failed: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected 2 results without filter, got %d", len(all))
+	}
+
+	current, err := idx.SearchFiltered("fetch", 10, SearchFilter{ExcludeDeprecated: true})
+	if err != nil {
+		t.Fatalf("SearchFiltered failed: %v", err)
+	}
+	if len(current) != 1 || current[0].ID != "web:fetch2" {
+		t.Fatalf("expected only web:fetch2, got %+v", current)
+	}
+
+	page, _, err := idx.SearchPageFiltered("", 10, "", SearchFilter{ExcludeDeprecated: true})
+	if err != nil {
+		t.Fatalf("SearchPageFiltered failed: %v", err)
+	}
+	if len(page) != 1 {
+		t.Fatalf("expected 1 paged result, got %d", len(page))
+	}
+}
+
+func TestSearchFiltered_RequireOutputSchema(t *testing.T) {
+	idx := NewInMemoryIndex()
+	withOutput := makeTestTool("parse", "text", "Parse text", nil)
+	withOutput.OutputSchema = map[string]any{"type": "object"}
+	emptyOutput := makeTestTool("render", "text", "Render text", nil)
+	emptyOutput.OutputSchema = json.RawMessage(" { } ")
+	mustRegister(t, idx, withOutput, makeLocalBackend("parse"))
+	mustRegister(t, idx, emptyOutput, makeLocalBackend("render"))
+	mustRegister(t, idx, makeTestTool("print", "text", "Print text", nil), makeLocalBackend("print"))
+
+	all, _ := idx.SearchFiltered("text", 10, SearchFilter{})
+	if len(all) != 3 {
+		t.Fatalf("expected 3 results without filter, got %+v", all)
+	}
+	composable, err := idx.SearchFiltered("text", 10, SearchFilter{RequireOutputSchema: true})
+	if err != nil {
+		t.Fatalf("SearchFiltered failed: %v", err)
+	}
+	if len(composable) != 1 || composable[0].ID != "text:parse" {
+		t.Fatalf("expected only the tool with an output schema, got %+v", composable)
+	}
+}
+
+func TestSearchFiltered_ExcludeNamespaces(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("fetch", "web", "Fetch", nil), makeLocalBackend("a"))
+	mustRegister(t, idx, makeTestTool("fetch", "test", "Fetch", nil), makeLocalBackend("b"))
+	mustRegister(t, idx, makeTestTool("fetch", "sandbox", "Fetch", nil), makeLocalBackend("c"))
+
+	results, err := idx.SearchFiltered("fetch", 2, SearchFilter{ExcludeNamespaces: []string{"test", "sandbox"}})
+	if err != nil {
+		t.Fatalf("SearchFiltered failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "web:fetch" {
+		t.Fatalf("expected only web:fetch, got %+v", results)
+	}
+}
+
+func TestSearchFiltered_RegistrationOrder(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("zeta", "ns", "Zeta", nil), makeLocalBackend("z"))
+	mustRegister(t, idx, makeTestTool("alpha", "ns", "Alpha", nil), makeLocalBackend("a"))
+	mustRegister(t, idx, makeTestTool("mid", "ns", "Mid", nil), makeLocalBackend("m"))
+	// Re-registration keeps the original position.
+	mustRegister(t, idx, makeTestTool("zeta", "ns", "Zeta", nil), makeMCPBackend("z"))
+
+	ids := func(results []Summary) []string {
+		out := make([]string, len(results))
+		for i, r := range results {
+			out[i] = r.ID
+		}
+		return out
+	}
+
+	results, err := idx.SearchFiltered("", 10, SearchFilter{RegistrationOrder: true})
+	if err != nil {
+		t.Fatalf("SearchFiltered failed: %v", err)
+	}
+	if got, want := ids(results), []string{"ns:zeta", "ns:alpha", "ns:mid"}; !slices.Equal(got, want) {
+		t.Fatalf("expected registration order %v, got %v", want, got)
+	}
+
+	results, _ = idx.Search("", 10)
+	if got, want := ids(results), []string{"ns:alpha", "ns:mid", "ns:zeta"}; !slices.Equal(got, want) {
+		t.Fatalf("expected ID order by default %v, got %v", want, got)
+	}
+
+	page, next, err := idx.SearchPageFiltered("", 2, "", SearchFilter{RegistrationOrder: true})
+	if err != nil || next == "" {
+		t.Fatalf("SearchPageFiltered failed: %v (next %q)", err, next)
+	}
+	rest, _, err := idx.SearchPageFiltered("", 2, next, SearchFilter{RegistrationOrder: true})
+	if err != nil {
+		t.Fatalf("SearchPageFiltered failed: %v", err)
+	}
+	if got, want := ids(append(page, rest...)), []string{"ns:zeta", "ns:alpha", "ns:mid"}; !slices.Equal(got, want) {
+		t.Fatalf("expected paged registration order %v, got %v", want, got)
+	}
+}
+
+func TestSearchFiltered_Categories(t *testing.T) {
+	idx := NewInMemoryIndex()
+	err := idx.RegisterTools([]ToolRegistration{
+		{Tool: makeTestTool("send", "slack", "Send a message", nil), Backend: makeLocalBackend("a"), Category: "Communication"},
+		{Tool: makeTestTool("deploy", "ci", "Deploy a build", nil), Backend: makeLocalBackend("b"), Category: "Dev Ops"},
+		{Tool: makeTestTool("email", "mail", "Send an email", nil), Backend: makeLocalBackend("c"), Category: "communication"},
+	})
+	if err != nil {
+		t.Fatalf("RegisterTools failed: %v", err)
+	}
+
+	results, err := idx.SearchFiltered("", 10, SearchFilter{Categories: []string{"Communication"}})
+	if err != nil {
+		t.Fatalf("SearchFiltered failed: %v", err)
+	}
+	if len(results) != 2 || results[0].ID != "mail:email" || results[1].ID != "slack:send" {
+		t.Fatalf("expected communication tools, got %+v", results)
+	}
+	if results, _ := idx.SearchFiltered("", 10, SearchFilter{Categories: []string{"!!!"}}); len(results) != 0 {
+		t.Fatalf("expected unmatchable category filter to return nothing, got %+v", results)
+	}
+}
+
+func TestSearchFiltered_MaxPerBackendKind(t *testing.T) {
+	idx := NewInMemoryIndex()
+	for _, name := range []string{"fetch_a", "fetch_b", "fetch_c"} {
+		mustRegister(t, idx, makeTestTool(name, "remote", "Fetch", nil), makeMCPBackend("srv"))
+	}
+	mustRegister(t, idx, makeTestTool("fetch_local", "local", "Fetch", nil), makeLocalBackend("l"))
+	// The default selector prefers local, so this tool counts as local.
+	mustRegister(t, idx, makeTestTool("fetch_d", "remote", "Fetch", nil), makeMCPBackend("srv"))
+	mustRegister(t, idx, makeTestTool("fetch_d", "remote", "Fetch", nil), makeLocalBackend("d"))
+
+	results, err := idx.SearchFiltered("fetch", 10, SearchFilter{MaxPerBackendKind: 1})
+	if err != nil {
+		t.Fatalf("SearchFiltered failed: %v", err)
+	}
+	got := make([]string, len(results))
+	for i, r := range results {
+		got[i] = r.ID
+	}
+	if want := []string{"local:fetch_local", "remote:fetch_a"}; !slices.Equal(got, want) {
+		t.Fatalf("expected one result per kind %v, got %v", want, got)
+	}
+
+	if results, _ := idx.SearchFiltered("fetch", 10, SearchFilter{MaxPerBackendKind: 2}); len(results) != 4 {
+		t.Fatalf("expected two per kind, got %+v", results)
+	}
+	if results, _ := idx.SearchFiltered("fetch", 1, SearchFilter{MaxPerBackendKind: 2}); len(results) != 1 {
+		t.Fatalf("expected limit to apply after capping, got %+v", results)
+	}
+
+	page, next, err := idx.SearchPageFiltered("fetch", 10, "", SearchFilter{MaxPerBackendKind: 1})
+	if err != nil || next != "" || len(page) != 2 {
+		t.Fatalf("expected capped single page, got %+v (next %q, err %v)", page, next, err)
+	}
+}
+
+func TestSearch_MaxSearchLimit(t *testing.T) {
+	var seenLimit int
+	idx := NewInMemoryIndex(IndexOptions{MaxSearchLimit: 2})
+	for _, name := range []string{"a", "b", "c", "d"} {
+		mustRegister(t, idx, makeTestTool(name, "ns", "desc", nil), makeLocalBackend(name))
+	}
+
+	results, err := idx.Search("", 1000000)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected limit clamped to 2, got %d results", len(results))
+	}
+
+	page, next, err := idx.SearchPage("", 1000000, "")
+	if err != nil {
+		t.Fatalf("SearchPage failed: %v", err)
+	}
+	if len(page) != 2 || next == "" {
+		t.Fatalf("expected a clamped page of 2 with a cursor, got %d results, cursor %q", len(page), next)
+	}
+
+	idx.SetSearcher(&mockSearcher{
+		searchFunc: func(_ string, limit int, _ []SearchDoc) ([]Summary, error) {
+			seenLimit = limit
+			return nil, nil
+		},
+	})
+	if _, err := idx.Search("x", 50); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if seenLimit != 2 {
+		t.Errorf("expected searcher to receive clamped limit 2, got %d", seenLimit)
+	}
+}
+
+func TestSearch_SeparatorsInterchangeable(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("get_user", "accounts", "Fetch an account", nil), makeLocalBackend("a"))
+	mustRegister(t, idx, makeTestTool("get-user", "legacy", "Fetch an account", nil), makeLocalBackend("b"))
+
+	for _, query := range []string{"get user", "get_user", "get-user", "GET USER"} {
+		results, err := idx.Search(query, 10)
+		if err != nil {
+			t.Fatalf("Search(%q) failed: %v", query, err)
+		}
+		if len(results) != 2 {
+			t.Fatalf("Search(%q): expected both tools, got %+v", query, results)
+		}
+		if results[0].Name != "get_user" || results[1].Name != "get-user" {
+			t.Errorf("Search(%q): expected original names kept for display, got %+v", query, results)
+		}
+	}
+}
+
+func TestSearch_SplitCamelCase(t *testing.T) {
+	tool := makeTestTool("getUserProfile", "crm", "Load a profile", nil)
+
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, tool, makeLocalBackend("p"))
+	if results, _ := idx.Search("get user", 10); len(results) != 0 {
+		t.Fatalf("expected no word-boundary match by default, got %+v", results)
+	}
+
+	idx = NewInMemoryIndex(IndexOptions{SplitCamelCase: true})
+	mustRegister(t, idx, tool, makeLocalBackend("p"))
+	results, err := idx.Search("get user", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "getUserProfile" {
+		t.Fatalf("expected camelCase match with display name intact, got %+v", results)
+	}
+}
+
+func TestSplitCamelCase(t *testing.T) {
+	tests := map[string]string{
+		"getUserProfile": "get User Profile",
+		"HTTPServer":     "HTTP Server",
+		"parseJSON":      "parse JSON",
+		"v2Api":          "v2 Api",
+		"lower":          "lower",
+		"":               "",
+	}
+	for in, want := range tests {
+		if got := splitCamelCase(in); got != want {
+			t.Errorf("splitCamelCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestOverview(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("read", "files", "Read a file", nil), makeLocalBackend("r"))
+	mustRegister(t, idx, makeTestTool("issue", "github", "Open an issue", nil), makeLocalBackend("i"))
+	mustRegister(t, idx, makeTestTool("secret", "admin", "Read secrets", nil), makeLocalBackend("s"))
+	if err := idx.SetHidden("admin:secret", true); err != nil {
+		t.Fatalf("SetHidden failed: %v", err)
+	}
+
+	results, namespaces, version, err := idx.Overview("read", 10)
+	if err != nil {
+		t.Fatalf("Overview failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "files:read" {
+		t.Fatalf("expected files:read, got %+v", results)
+	}
+	if !slices.Equal(namespaces, []string{"files", "github"}) {
+		t.Fatalf("expected visible namespaces, got %v", namespaces)
+	}
+	if version != idx.Stats().Version {
+		t.Fatalf("expected version %d, got %d", idx.Stats().Version, version)
+	}
+
+	_, _, again, _ := idx.Overview("read", 10)
+	if again != version {
+		t.Fatalf("expected unchanged version without mutation, got %d then %d", version, again)
+	}
+	mustRegister(t, idx, makeTestTool("write", "files", "Write a file", nil), makeLocalBackend("w"))
+	if _, _, after, _ := idx.Overview("read", 10); after == version {
+		t.Fatal("expected version to change after a mutation")
+	}
+}
+
+func TestSearchIDs(t *testing.T) {
+	idx := NewInMemoryIndex(IndexOptions{MaxSearchLimit: 2})
+	mustRegister(t, idx, makeTestTool("add", "math", "Add numbers", nil), makeLocalBackend("a"))
+	mustRegister(t, idx, makeTestTool("adder", "math", "Another adder", nil), makeLocalBackend("b"))
+	mustRegister(t, idx, makeTestTool("fetch", "web", "Add a bookmark", nil), makeLocalBackend("c"))
+
+	results, _ := idx.Search("add", 10)
+	ids, err := idx.SearchIDs("add", 10)
+	if err != nil {
+		t.Fatalf("SearchIDs failed: %v", err)
+	}
+	if !slices.Equal(ids, summaryIDs(results)) || len(ids) != 2 {
+		t.Fatalf("expected IDs %v matching Search, got %v", summaryIDs(results), ids)
+	}
+
+	idx.SetSearcher(&mockSearcher{searchFunc: func(string, int, []SearchDoc) ([]Summary, error) {
+		return []Summary{{ID: "web:fetch"}}, nil
+	}})
+	if ids, _ := idx.SearchIDs("add", 10); !slices.Equal(ids, []string{"web:fetch"}) {
+		t.Fatalf("expected custom searcher IDs, got %v", ids)
+	}
+}
+
+func TestSearchGrouped(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("add", "math", "Add numbers", nil), makeLocalBackend("a"))
+	mustRegister(t, idx, makeTestTool("add_all", "math", "Add many numbers", nil), makeLocalBackend("b"))
+	mustRegister(t, idx, makeTestTool("adder", "math", "Another adder", nil), makeLocalBackend("c"))
+	mustRegister(t, idx, makeTestTool("fetch", "web", "Add a bookmark", nil), makeLocalBackend("d"))
+
+	groups, err := idx.SearchGrouped("add", 2)
+	if err != nil {
+		t.Fatalf("SearchGrouped failed: %v", err)
+	}
+	if len(groups) != 2 || groups[0].Namespace != "math" || groups[1].Namespace != "web" {
+		t.Fatalf("expected math then web groups, got %+v", groups)
+	}
+	if len(groups[0].Results) != 2 || groups[0].Results[0].ID != "math:add" {
+		t.Fatalf("expected capped math group led by exact match, got %+v", groups[0].Results)
+	}
+	if len(groups[1].Results) != 1 || groups[1].Results[0].ID != "web:fetch" {
+		t.Fatalf("unexpected web group: %+v", groups[1].Results)
+	}
+}
+
+func TestSearchGrouped_ClampsLimit(t *testing.T) {
+	var seenLimit int
+	idx := NewInMemoryIndex(IndexOptions{MaxSearchLimit: 2})
+	for _, name := range []string{"a", "b", "c"} {
+		mustRegister(t, idx, makeTestTool(name, "one", "desc", nil), makeLocalBackend(name))
+		mustRegister(t, idx, makeTestTool(name, "two", "desc", nil), makeLocalBackend(name))
+	}
+
+	groups, err := idx.SearchGrouped("desc", 1000000)
+	if err != nil {
+		t.Fatalf("SearchGrouped failed: %v", err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("expected two groups, got %+v", groups)
+	}
+	for _, group := range groups {
+		if len(group.Results) > 2 {
+			t.Fatalf("expected groups clamped to 2 results, got %+v", group)
+		}
+	}
+
+	idx.SetSearcher(&mockSearcher{
+		searchFunc: func(_ string, limit int, _ []SearchDoc) ([]Summary, error) {
+			seenLimit = limit
+			return nil, nil
+		},
+	})
+	if _, err := idx.SearchGrouped("desc", 1000000); err != nil {
+		t.Fatalf("SearchGrouped failed: %v", err)
+	}
+	if seenLimit != 4 {
+		t.Fatalf("expected searcher window of 2 per namespace (4), got %d", seenLimit)
+	}
+}
+
+func TestSearch_QualifiedNameBoost(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("calc", "math", "Evaluate an expression", nil), makeLocalBackend("a"))
+	// Matches "calc" by name alone and would otherwise tie or win on ID.
+	mustRegister(t, idx, makeTestTool("calc", "finance", "Compute math for loans", nil), makeLocalBackend("b"))
+
+	for _, query := range []string{"math calc", "math.calc", "math:calc", "calc math", "Math Calc"} {
+		results, err := idx.Search(query, 10)
+		if err != nil {
+			t.Fatalf("Search(%q) failed: %v", query, err)
+		}
+		if len(results) == 0 || results[0].ID != "math:calc" {
+			t.Errorf("Search(%q): expected math:calc first, got %+v", query, results)
+		}
+	}
+}
+
+func TestSearch_NameLengthPenalty(t *testing.T) {
+	register := func(idx *InMemoryIndex) {
+		mustRegister(t, idx, makeTestTool("calc_advanced_scientific_v2", "a", "Calculator", nil), makeLocalBackend("a"))
+		mustRegister(t, idx, makeTestTool("calculator", "b", "Calculator", nil), makeLocalBackend("b"))
+	}
+
+	idx := NewInMemoryIndex()
+	register(idx)
+	results, _ := idx.Search("calc", 10)
+	if len(results) != 2 || results[0].ID != "a:calc_advanced_scientific_v2" {
+		t.Fatalf("expected ID order without penalty, got %+v", results)
+	}
+
+	idx = NewInMemoryIndex(IndexOptions{NameLengthPenalty: 0.2})
+	register(idx)
+	results, _ = idx.Search("calc", 10)
+	if len(results) != 2 || results[0].ID != "b:calculator" {
+		t.Fatalf("expected shorter name first with penalty, got %+v", results)
+	}
+}
+
+func TestSearch_PrefixMatchRanksHigher(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("miscalc-helper", "a", "Helper", nil), makeLocalBackend("a"))
+	mustRegister(t, idx, makeTestTool("calculator", "b", "Helper", nil), makeLocalBackend("b"))
+
+	results, _ := idx.Search("calc", 10)
+	if len(results) != 2 || results[0].ID != "b:calculator" || results[1].ID != "a:miscalc-helper" {
+		t.Fatalf("expected prefix match first and substring match kept, got %+v", results)
+	}
+
+	// Tags starting with the query earn the prefix bonus on top of the tag match.
+	mustRegister(t, idx, makeTestTool("other", "d", "Helper", []string{"calculus"}), makeLocalBackend("d"))
+	explanations, _ := idx.SearchExplain("calc", 10)
+	for _, e := range explanations {
+		if e.Summary.ID == "d:other" && e.Score != 35 {
+			t.Errorf("expected tag match plus prefix bonus, got %+v", e)
+		}
+	}
+}
+
+func TestSearch_MultiFieldMatchesAccumulate(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("deploy_app", "ops", "Ship it", nil), makeLocalBackend("a"))
+	mustRegister(t, idx, makeTestTool("deploy_web", "ops", "Ship it", []string{"web-deploy"}), makeLocalBackend("b"))
+	mustRegister(t, idx, makeTestTool("deploy", "deploy", "Ship it", nil), makeLocalBackend("c"))
+	mustRegister(t, idx, makeTestTool("ship", "ops", "Deploy things", nil), makeLocalBackend("d"))
+
+	results, _ := idx.Search("deploy", 10)
+	want := []string{"deploy:deploy", "ops:deploy_web", "ops:deploy_app", "ops:ship"}
+	if got := summaryIDs(results); !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	explanations, _ := idx.SearchExplain("deploy", 10)
+	scores := make(map[string]int)
+	for _, e := range explanations {
+		scores[e.Summary.ID] = e.Score
+	}
+	// name 100 + prefix 25, plus 10 for the tag; description-only stays at 10.
+	if scores["ops:deploy_web"] != 135 || scores["ops:deploy_app"] != 125 || scores["ops:ship"] != 10 {
+		t.Fatalf("unexpected scores: %v", scores)
+	}
+}
+
 // ============================================================
 // Tests for Summary Results
 // ============================================================
@@ -1227,24 +2657,174 @@ func TestSearch_NormalizedTagsInSummary(t *testing.T) {
 	}
 }
 
-// ============================================================
-// Tests for Tag Normalization on Ingest
-// ============================================================
-
-func TestTagNormalization_OnIngest(t *testing.T) {
-	idx := NewInMemoryIndex()
+func TestSummary_PreviewOptIn(t *testing.T) {
+	longDesc := strings.Repeat("é", 400)
+
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("tool", "ns", longDesc, nil), makeLocalBackend("t"))
+	results, _ := idx.Search("tool", 1)
+	if results[0].Preview != "" {
+		t.Fatalf("expected no preview by default, got %d chars", len(results[0].Preview))
+	}
+
+	idx = NewInMemoryIndex(IndexOptions{PreviewLen: 300})
+	mustRegister(t, idx, makeTestTool("tool", "ns", longDesc, nil), makeLocalBackend("t"))
+	results, _ = idx.Search("tool", 1)
+	if got := len([]rune(results[0].Preview)); got != 300 {
+		t.Fatalf("expected 300-rune preview, got %d", got)
+	}
+	if len(results[0].ShortDescription) != MaxShortDescriptionLen {
+		t.Fatalf("ShortDescription should be unaffected, got %d bytes", len(results[0].ShortDescription))
+	}
+}
+
+// ============================================================
+// Tests for Tag Normalization on Ingest
+// ============================================================
+
+func TestTagNormalization_OnIngest(t *testing.T) {
+	idx := NewInMemoryIndex()
+
+	tool := makeTestTool("mytool", "ns", "desc", []string{"  TAG ONE  ", "TAG-TWO"})
+	mustRegister(t, idx, tool, makeMCPBackend("s"))
+
+	// Search should work with normalized tags
+	results, err := idx.Search("tag-one", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if len(results) == 0 {
+		t.Fatal("expected search to find tool by normalized tag")
+	}
+}
+
+func TestRegisterTools_TagsNormalized(t *testing.T) {
+	trusted := func(tags []string) []ToolRegistration {
+		return []ToolRegistration{{
+			Tool:           makeTestTool("mytool", "ns", "desc", tags),
+			Backend:        makeMCPBackend("s"),
+			TagsNormalized: true,
+		}}
+	}
+
+	idx := NewInMemoryIndex()
+	if err := idx.RegisterTools(trusted([]string{"tag-one", "tag-two"})); err != nil {
+		t.Fatalf("RegisterTools failed: %v", err)
+	}
+	results, _ := idx.Search("tag-two", 10)
+	if len(results) != 1 || !slices.Equal(results[0].Tags, []string{"tag-one", "tag-two"}) {
+		t.Fatalf("expected trusted tags to be indexed as-is, got %+v", results)
+	}
+
+	idx = NewInMemoryIndex(IndexOptions{CheckNormalizedTags: true})
+	if err := idx.RegisterTools(trusted([]string{"tag-one"})); err != nil {
+		t.Fatalf("RegisterTools with normalized tags failed: %v", err)
+	}
+	if err := idx.RegisterTools(trusted([]string{"Tag One"})); !errors.Is(err, ErrInvalidTool) {
+		t.Fatalf("expected ErrInvalidTool for unnormalized trusted tags, got %v", err)
+	}
+}
+
+func TestPreserveTagCase_DedupesDisplayForms(t *testing.T) {
+	idx := NewInMemoryIndex(IndexOptions{PreserveTagCase: true})
+	tool := makeTestTool("pr", "vcs", "Open a pull request", []string{" GitHub ", "github", "Code Review", "!!!"})
+	mustRegister(t, idx, tool, makeMCPBackend("vcs"))
+
+	results, err := idx.Search("github", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected normalized tag to be searchable, got %+v", results)
+	}
+	if want := []string{"GitHub", "Code Review"}; !slices.Equal(results[0].Tags, want) {
+		t.Fatalf("expected display tags %v, got %v", want, results[0].Tags)
+	}
+
+	if results, _ := idx.Search("code-review", 10); len(results) != 1 {
+		t.Fatalf("expected normalized multi-word tag to be searchable, got %+v", results)
+	}
+}
+
+func TestMaxTagsPerTool_Truncates(t *testing.T) {
+	var truncatedID string
+	var dropped []string
+	idx := NewInMemoryIndex(IndexOptions{
+		MaxTagsPerTool: 2,
+		OnTagsTruncated: func(toolID string, tags []string) {
+			truncatedID, dropped = toolID, tags
+		},
+	})
+	tool := makeTestTool("mytool", "ns", "desc", []string{"Alpha", "beta", "alpha", "gamma", "delta"})
+	mustRegister(t, idx, tool, makeMCPBackend("s"))
+
+	results, _ := idx.Search("mytool", 10)
+	if len(results) != 1 || !slices.Equal(results[0].Tags, []string{"alpha", "beta"}) {
+		t.Fatalf("expected first two normalized tags, got %+v", results)
+	}
+	if truncatedID != "ns:mytool" || !slices.Equal(dropped, []string{"gamma", "delta"}) {
+		t.Fatalf("expected hook for ns:mytool with [gamma delta], got %q %v", truncatedID, dropped)
+	}
+	if results, _ := idx.Search("gamma", 10); len(results) != 0 {
+		t.Fatalf("expected dropped tag not to be searchable, got %+v", results)
+	}
+	stored, _, _ := idx.GetTool("ns:mytool")
+	if len(stored.Tags) != 5 {
+		t.Fatalf("expected stored tool tags to be unchanged, got %v", stored.Tags)
+	}
+
+	// Upserts are capped the same way.
+	dropped = nil
+	if err := idx.UpsertTool(makeTestTool("other", "ns", "desc", []string{"a", "b", "c"}), []toolmodel.ToolBackend{makeMCPBackend("s")}); err != nil {
+		t.Fatalf("UpsertTool failed: %v", err)
+	}
+	if !slices.Equal(dropped, []string{"c"}) {
+		t.Fatalf("expected upsert to drop [c], got %v", dropped)
+	}
+}
+
+func TestMaxTagsPerTool_AllTagPaths(t *testing.T) {
+	reports := map[string][]string{}
+	idx := NewInMemoryIndex(IndexOptions{
+		MaxTagsPerTool: 2,
+		OnTagsTruncated: func(toolID string, tags []string) {
+			reports[toolID] = append(reports[toolID], tags...)
+		},
+	})
+	mustRegister(t, idx, makeTestTool("full", "ns", "desc", []string{"a", "b"}), makeMCPBackend("s"))
+	mustRegister(t, idx, makeTestTool("roomy", "ns", "desc", []string{"a"}), makeMCPBackend("s"))
 
-	tool := makeTestTool("mytool", "ns", "desc", []string{"  TAG ONE  ", "TAG-TWO"})
-	mustRegister(t, idx, tool, makeMCPBackend("s"))
+	// A rejected registration reports nothing.
+	conflicting := makeTestTool("full", "ns", "changed", []string{"a", "b", "c"})
+	if err := idx.RegisterTool(conflicting, makeMCPBackend("s")); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+	if len(reports) != 0 {
+		t.Fatalf("expected no truncation report for a rejected registration, got %v", reports)
+	}
 
-	// Search should work with normalized tags
-	results, err := idx.Search("tag-one", 10)
-	if err != nil {
-		t.Fatalf("Search failed: %v", err)
+	n, err := idx.TagNamespace("ns", "extra")
+	if err != nil || n != 1 {
+		t.Fatalf("expected only the tool with room to gain the tag, got %d, %v", n, err)
+	}
+	if !slices.Equal(reports["ns:full"], []string{"extra"}) {
+		t.Fatalf("expected ns:full to report the dropped tag, got %v", reports)
+	}
+	if results, _ := idx.Search("extra", 10); len(results) != 1 || results[0].ID != "ns:roomy" {
+		t.Fatalf("expected tag only on ns:roomy, got %+v", results)
 	}
 
-	if len(results) == 0 {
-		t.Fatal("expected search to find tool by normalized tag")
+	reports = map[string][]string{}
+	mustRegister(t, idx, makeTestTool("other", "ns", "desc", []string{"c", "d"}), makeMCPBackend("s"))
+	if err := idx.MergeTool("ns:other", "ns:full"); err != nil {
+		t.Fatalf("MergeTool failed: %v", err)
+	}
+	if !slices.Equal(reports["ns:full"], []string{"c", "d"}) {
+		t.Fatalf("expected merged tags beyond the cap to be reported, got %v", reports)
+	}
+	if summary, _ := idx.GetSummary("ns:full"); len(summary.Tags) != 2 {
+		t.Fatalf("expected merged tags capped at 2, got %v", summary.Tags)
 	}
 }
 
@@ -1283,6 +2863,228 @@ func (m *mockSearcher) Search(query string, limit int, docs []SearchDoc) ([]Summ
 	return m.searchFunc(query, limit, docs)
 }
 
+func TestReady(t *testing.T) {
+	idx := NewInMemoryIndex()
+	if err := idx.Ready(); err != nil {
+		t.Fatalf("Ready on empty index: %v", err)
+	}
+
+	failing := &mockSearcher{
+		searchFunc: func(_ string, _ int, _ []SearchDoc) ([]Summary, error) {
+			return nil, errors.New("backend down")
+		},
+	}
+	idx = NewInMemoryIndex(IndexOptions{Searcher: failing})
+	if err := idx.Ready(); !errors.Is(err, ErrNotReady) {
+		t.Fatalf("expected ErrNotReady, got %v", err)
+	}
+}
+
+type prebuildMockSearcher struct {
+	mockSearcher
+	prebuiltDocs    int
+	prebuiltVersion uint64
+}
+
+func (p *prebuildMockSearcher) Prebuild(docs []SearchDoc, version uint64) {
+	p.prebuiltDocs = len(docs)
+	p.prebuiltVersion = version
+}
+
+func TestSetSearcher_SwapsAndPrebuilds(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("alpha", "ns", "Alpha", nil), makeLocalBackend("a"))
+	mustRegister(t, idx, makeTestTool("beta", "ns", "Beta", nil), makeLocalBackend("b"))
+
+	custom := &prebuildMockSearcher{mockSearcher: mockSearcher{
+		searchFunc: func(_ string, _ int, _ []SearchDoc) ([]Summary, error) {
+			return []Summary{{ID: "custom"}}, nil
+		},
+	}}
+	idx.SetSearcher(custom)
+	if custom.prebuiltDocs != 2 || custom.prebuiltVersion == 0 {
+		t.Fatalf("expected prebuild with 2 docs, got %d at version %d", custom.prebuiltDocs, custom.prebuiltVersion)
+	}
+
+	results, err := idx.Search("alpha", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "custom" {
+		t.Fatalf("expected custom searcher results, got %+v", results)
+	}
+
+	idx.SetSearcher(nil)
+	results, _ = idx.Search("alpha", 10)
+	if len(results) != 1 || results[0].ID != "ns:alpha" {
+		t.Fatalf("expected default searcher after reset, got %+v", results)
+	}
+}
+
+func TestWarmup_PrebuildsConfiguredSearcher(t *testing.T) {
+	custom := &prebuildMockSearcher{mockSearcher: mockSearcher{
+		searchFunc: func(_ string, _ int, _ []SearchDoc) ([]Summary, error) {
+			return nil, nil
+		},
+	}}
+	idx := NewInMemoryIndex(IndexOptions{Searcher: custom})
+	mustRegister(t, idx, makeTestTool("alpha", "ns", "Alpha", nil), makeLocalBackend("a"))
+	mustRegister(t, idx, makeTestTool("beta", "ns", "Beta", nil), makeLocalBackend("b"))
+	if custom.prebuiltDocs != 0 {
+		t.Fatalf("expected no prebuild before Warmup, got %d docs", custom.prebuiltDocs)
+	}
+
+	version := idx.Warmup()
+	if custom.prebuiltDocs != 2 || custom.prebuiltVersion != version {
+		t.Fatalf("expected prebuild with 2 docs at version %d, got %d at %d", version, custom.prebuiltDocs, custom.prebuiltVersion)
+	}
+	before := idx.Stats().SearchDocCacheMisses
+	_, _ = idx.Search("alpha", 10)
+	if idx.Stats().SearchDocCacheMisses != before {
+		t.Error("expected first search after Warmup to hit the doc cache")
+	}
+}
+
+// cachingSearcher rebuilds its state only when the doc version changes.
+type cachingSearcher struct {
+	version uint64
+	builds  int
+}
+
+func (c *cachingSearcher) Search(string, int, []SearchDoc) ([]Summary, error) {
+	return nil, errors.New("Search called instead of SearchVersioned")
+}
+
+func (c *cachingSearcher) SearchVersioned(_ string, _ int, docs []SearchDoc, version uint64) ([]Summary, error) {
+	if version != c.version {
+		c.version = version
+		c.builds++
+	}
+	results := make([]Summary, len(docs))
+	for i, doc := range docs {
+		results[i] = doc.Summary
+	}
+	return results, nil
+}
+
+func TestVersionedSearcher_ReceivesVersion(t *testing.T) {
+	custom := &cachingSearcher{}
+	idx := NewInMemoryIndex(IndexOptions{Searcher: custom})
+	mustRegister(t, idx, makeTestTool("alpha", "ns", "Alpha", nil), makeLocalBackend("a"))
+
+	for range 3 {
+		if _, err := idx.Search("alpha", 10); err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+	}
+	if custom.builds != 1 || custom.version != idx.Stats().Version {
+		t.Fatalf("expected one build at current version, got %d builds at %d", custom.builds, custom.version)
+	}
+
+	mustRegister(t, idx, makeTestTool("beta", "ns", "Beta", nil), makeLocalBackend("b"))
+	results, _ := idx.Search("beta", 10)
+	if custom.builds != 2 || len(results) != 2 {
+		t.Fatalf("expected rebuild after mutation, got %d builds and %d results", custom.builds, len(results))
+	}
+}
+
+func TestSetSearcher_ConcurrentWithSearch(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("alpha", "ns", "Alpha", nil), makeLocalBackend("a"))
+	custom := &nondeterministicSearcher{}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			if i%2 == 0 {
+				idx.SetSearcher(custom)
+			} else {
+				idx.SetSearcher(nil)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			if _, err := idx.Search("alpha", 10); err != nil {
+				t.Errorf("Search failed: %v", err)
+			}
+		}
+	}()
+	wg.Wait()
+}
+
+func TestSearcherFallback(t *testing.T) {
+	primaryErr := errors.New("vector store unavailable")
+	var reported []error
+	idx := NewInMemoryIndex(IndexOptions{
+		Searcher: &mockSearcher{
+			searchFunc: func(_ string, _ int, _ []SearchDoc) ([]Summary, error) {
+				return nil, primaryErr
+			},
+		},
+		SearcherFallback: &lexicalSearcher{},
+		OnSearcherError:  func(err error) { reported = append(reported, err) },
+	})
+	mustRegister(t, idx, makeTestTool("fetch", "web", "Fetch a page", nil), makeLocalBackend("f"))
+
+	results, err := idx.Search("fetch", 10)
+	if err != nil {
+		t.Fatalf("Search should degrade to fallback, got %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "web:fetch" {
+		t.Fatalf("unexpected fallback results: %+v", results)
+	}
+	if len(reported) != 1 || !errors.Is(reported[0], primaryErr) {
+		t.Fatalf("expected primary error to be reported, got %v", reported)
+	}
+
+	// Without a fallback the error propagates.
+	idx = NewInMemoryIndex(IndexOptions{Searcher: &mockSearcher{
+		searchFunc: func(_ string, _ int, _ []SearchDoc) ([]Summary, error) {
+			return nil, primaryErr
+		},
+	}})
+	if _, err := idx.Search("fetch", 10); !errors.Is(err, primaryErr) {
+		t.Fatalf("expected primary error, got %v", err)
+	}
+}
+
+func TestSearchWithWarnings(t *testing.T) {
+	failing := true
+	idx := NewInMemoryIndex(IndexOptions{
+		Searcher: &mockSearcher{
+			searchFunc: func(query string, limit int, docs []SearchDoc) ([]Summary, error) {
+				if failing {
+					return nil, errors.New("rerank service down")
+				}
+				return (&lexicalSearcher{}).Search(query, limit, docs)
+			},
+		},
+		SearcherFallback: &lexicalSearcher{},
+	})
+	mustRegister(t, idx, makeTestTool("fetch", "web", "Fetch a page", nil), makeLocalBackend("f"))
+
+	results, warnings, err := idx.SearchWithWarnings("fetch", 10)
+	if err != nil {
+		t.Fatalf("SearchWithWarnings failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected fallback results, got %+v", results)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "rerank service down") {
+		t.Fatalf("expected degraded warning, got %v", warnings)
+	}
+
+	failing = false
+	results, warnings, err = idx.SearchWithWarnings("fetch", 10)
+	if err != nil || len(results) != 1 || len(warnings) != 0 {
+		t.Fatalf("expected clean results without warnings, got %+v, %v, %v", results, warnings, err)
+	}
+}
+
 // ============================================================
 // Tests for Thread Safety
 // ============================================================
@@ -1332,6 +3134,47 @@ func TestConcurrentAccess(t *testing.T) {
 	}
 }
 
+func TestSnapshotSearchDocs_RebuildRacesWithWriters(t *testing.T) {
+	idx := NewInMemoryIndex()
+	const writers, perWriter = 4, 50
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				tool := makeTestTool(fmt.Sprintf("tool%d_%d", w, i), "ns", "desc", nil)
+				if err := idx.RegisterTool(tool, makeLocalBackend("l")); err != nil {
+					t.Errorf("register: %v", err)
+				}
+			}
+		}()
+	}
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				docs, version := idx.snapshotSearchDocs()
+				// A snapshot holds exactly the tools registered by its version.
+				if uint64(len(docs)) > version {
+					t.Errorf("snapshot at version %d has %d docs", version, len(docs))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	docs, version := idx.snapshotSearchDocs()
+	if len(docs) != writers*perWriter || version != idx.Stats().Version {
+		t.Fatalf("expected %d docs at version %d, got %d at %d", writers*perWriter, idx.Stats().Version, len(docs), version)
+	}
+	if !slices.IsSortedFunc(docs, func(a, b SearchDoc) int { return strings.Compare(a.ID, b.ID) }) {
+		t.Fatal("expected docs sorted by ID")
+	}
+}
+
 // ============================================================
 // Tests for SearchDoc struct (exported for custom searchers)
 // ============================================================
@@ -1369,6 +3212,79 @@ func TestSearchDoc_ExposedToSearcher(t *testing.T) {
 	}
 }
 
+func TestSearchDoc_TokensPrecomputed(t *testing.T) {
+	var received []SearchDoc
+	idx := NewInMemoryIndex(IndexOptions{Searcher: &mockSearcher{
+		searchFunc: func(_ string, _ int, docs []SearchDoc) ([]Summary, error) {
+			received = docs
+			return nil, nil
+		},
+	}})
+	mustRegister(t, idx, makeTestTool("get_user", "crm", "Fetch a user, by ID.", []string{"people"}), makeLocalBackend("u"))
+
+	_, _ = idx.Search("user", 10)
+	if len(received) != 1 {
+		t.Fatalf("expected 1 doc, got %d", len(received))
+	}
+	want := []string{"get", "user", "crm", "fetch", "a", "user", "by", "id", "people"}
+	if fmt.Sprint(received[0].Tokens) != fmt.Sprint(want) {
+		t.Fatalf("tokens = %v, want %v", received[0].Tokens, want)
+	}
+
+	// Tokens refresh when searchable fields change.
+	mustRegister(t, idx, makeTestTool("get_user", "crm", "Fetch a user, by ID.", []string{"accounts"}), makeLocalBackend("u"))
+	_, _ = idx.Search("user", 10)
+	if got := received[0].Tokens[len(received[0].Tokens)-1]; got != "accounts" {
+		t.Fatalf("expected refreshed tag token, got %q", got)
+	}
+}
+
+func TestSearchDoc_WeightedFields(t *testing.T) {
+	var received []SearchDoc
+	searcher := &mockSearcher{
+		searchFunc: func(_ string, _ int, docs []SearchDoc) ([]Summary, error) {
+			received = docs
+			return nil, nil
+		},
+	}
+	tool := makeTestTool("getUser", "crm", "Fetch a user by user ID.", []string{"People"})
+
+	idx := NewInMemoryIndex(IndexOptions{Searcher: searcher})
+	mustRegister(t, idx, tool, makeLocalBackend("u"))
+	_, _ = idx.Search("user", 10)
+	if received[0].Fields != nil {
+		t.Fatalf("expected nil Fields by default, got %+v", received[0].Fields)
+	}
+
+	idx = NewInMemoryIndex(IndexOptions{Searcher: searcher, WeightedSearchDocs: true, SplitCamelCase: true})
+	mustRegister(t, idx, tool, makeLocalBackend("u"))
+	_, _ = idx.Search("user", 10)
+	fields := received[0].Fields
+	if fields == nil {
+		t.Fatal("expected Fields to be populated")
+	}
+	if fields.Name["user"] != 1 || fields.Name["get"] != 1 {
+		t.Errorf("Name tokens = %v", fields.Name)
+	}
+	if fields.Namespace["crm"] != 1 {
+		t.Errorf("Namespace tokens = %v", fields.Namespace)
+	}
+	if fields.Description["user"] != 2 || fields.Description["fetch"] != 1 {
+		t.Errorf("Description tokens = %v", fields.Description)
+	}
+	if fields.Tags["people"] != 1 || len(fields.Tags) != 1 {
+		t.Errorf("Tags tokens = %v", fields.Tags)
+	}
+
+	// Snapshots hand out copies the caller may modify.
+	snap := idx.SearchDocsSnapshot()
+	snap[0].Fields.Name["user"] = 99
+	_, _ = idx.Search("user", 10)
+	if received[0].Fields.Name["user"] != 1 {
+		t.Error("modifying a snapshot changed the indexed field tokens")
+	}
+}
+
 // ============================================================
 // Tests for Error Values
 // ============================================================
@@ -1500,7 +3416,7 @@ func TestSearchDocs_CachedBetweenSearches(t *testing.T) {
 	}
 }
 
-func TestSearchDocs_RebuildsAfterMutation(t *testing.T) {
+func TestSearchDocs_PatchedAfterMutation(t *testing.T) {
 	idx := NewInMemoryIndex()
 	mustRegister(t, idx, makeTestTool("tool1", "ns", "desc", nil), makeMCPBackend("s"))
 	if _, err := idx.Search("test", 10); err != nil {
@@ -1508,12 +3424,16 @@ func TestSearchDocs_RebuildsAfterMutation(t *testing.T) {
 	} // builds=1
 
 	mustRegister(t, idx, makeTestTool("tool2", "ns", "desc", nil), makeMCPBackend("s"))
-	if _, err := idx.Search("test", 10); err != nil {
+	results, err := idx.Search("tool2", 10)
+	if err != nil {
 		t.Fatalf("Search failed: %v", err)
-	} // builds=2
+	}
 
-	if idx.searchDocsBuilds != 2 {
-		t.Errorf("expected 2 doc builds after mutation, got %d", idx.searchDocsBuilds)
+	if len(results) != 1 || results[0].ID != "ns:tool2" {
+		t.Fatalf("expected patched cache to include the new tool, got %+v", results)
+	}
+	if idx.searchDocsBuilds != 1 || idx.searchDocsPatches != 1 {
+		t.Errorf("expected 1 build and 1 patch after mutation, got %d and %d", idx.searchDocsBuilds, idx.searchDocsPatches)
 	}
 }
 
@@ -1541,7 +3461,7 @@ func TestSearchDocs_ConcurrentDirtyCacheBuildsOnce(t *testing.T) {
 	}
 }
 
-func TestSearchDocs_RebuildsAfterUnregister(t *testing.T) {
+func TestSearchDocs_PatchedAfterUnregister(t *testing.T) {
 	idx := NewInMemoryIndex()
 	mustRegister(t, idx, makeTestTool("tool1", "ns", "desc", nil), makeMCPBackend("s1"))
 	mustRegister(t, idx, makeTestTool("tool2", "ns", "desc", nil), makeMCPBackend("s2"))
@@ -1552,12 +3472,41 @@ func TestSearchDocs_RebuildsAfterUnregister(t *testing.T) {
 	if err := idx.UnregisterBackend("ns:tool1", toolmodel.BackendKindMCP, "s1"); err != nil {
 		t.Fatalf("UnregisterBackend failed: %v", err)
 	}
-	if _, err := idx.Search("test", 10); err != nil {
+	results, err := idx.Search("tool", 10)
+	if err != nil {
 		t.Fatalf("Search failed: %v", err)
-	} // builds=2
+	}
+
+	if len(results) != 1 || results[0].ID != "ns:tool2" {
+		t.Fatalf("expected patched cache to drop the removed tool, got %+v", results)
+	}
+	if idx.searchDocsBuilds != 1 || idx.searchDocsPatches != 1 {
+		t.Errorf("expected 1 build and 1 patch after unregister, got %d and %d", idx.searchDocsBuilds, idx.searchDocsPatches)
+	}
+}
+
+func TestSearchDocs_PatchesMatchFullRebuild(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("bravo", "ns", "B", nil), makeLocalBackend("b"))
+	mustRegister(t, idx, makeTestTool("delta", "ns", "D", nil), makeLocalBackend("d"))
+	idx.SearchDocsSnapshot()
+
+	mustRegister(t, idx, makeTestTool("charlie", "ns", "C", nil), makeLocalBackend("c"))
+	mustRegister(t, idx, makeTestTool("alpha", "ns", "A", nil), makeLocalBackend("a"))
+	if err := idx.SetHidden("ns:delta", true); err != nil {
+		t.Fatalf("SetHidden failed: %v", err)
+	}
+	if err := idx.UnregisterBackend("ns:bravo", toolmodel.BackendKindLocal, "b"); err != nil {
+		t.Fatalf("UnregisterBackend failed: %v", err)
+	}
+	patched := idx.SearchDocsSnapshot()
+	if idx.searchDocsBuilds != 1 || idx.searchDocsPatches != 4 {
+		t.Fatalf("expected every mutation to be patched, got %d builds and %d patches", idx.searchDocsBuilds, idx.searchDocsPatches)
+	}
 
-	if idx.searchDocsBuilds != 2 {
-		t.Errorf("expected 2 doc builds after unregister, got %d", idx.searchDocsBuilds)
+	idx.Refresh()
+	if rebuilt := idx.SearchDocsSnapshot(); !reflect.DeepEqual(patched, rebuilt) {
+		t.Fatalf("patched docs differ from a full rebuild:\n%+v\n%+v", patched, rebuilt)
 	}
 }
 
@@ -1597,6 +3546,133 @@ func TestSearchDocs_DerivedFieldsRefreshOnUpdate(t *testing.T) {
 	}
 }
 
+func TestSearchDocsSnapshot_ReturnsCopy(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("beta", "ns", "Beta", []string{"x"}), makeLocalBackend("b"))
+	mustRegister(t, idx, makeTestTool("alpha", "ns", "Alpha", []string{"y"}), makeLocalBackend("a"))
+
+	docs := idx.SearchDocsSnapshot()
+	if len(docs) != 2 || docs[0].ID != "ns:alpha" || docs[1].ID != "ns:beta" {
+		t.Fatalf("unexpected snapshot: %+v", docs)
+	}
+	if !strings.Contains(docs[0].DocText, "alpha") {
+		t.Errorf("expected doc text to be populated, got %q", docs[0].DocText)
+	}
+
+	docs[0].Summary.Tags[0] = "mutated"
+	docs[1].DocText = "mutated"
+	for i := range docs[0].Tokens {
+		docs[0].Tokens[i] = "mutated"
+	}
+
+	again := idx.SearchDocsSnapshot()
+	if again[0].Summary.Tags[0] != "y" || again[1].DocText == "mutated" || again[0].Tokens[0] == "mutated" {
+		t.Fatalf("snapshot mutation leaked into index: %+v", again)
+	}
+	idx.SetSearcher(NewInvertedSearcher())
+	if results, _ := idx.Search("alpha", 10); len(results) != 1 || results[0].ID != "ns:alpha" {
+		t.Fatalf("expected token scoring to be unaffected, got %+v", results)
+	}
+}
+
+func TestSnapshotSummaries(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("beta", "ns", "Beta", []string{"x"}), makeLocalBackend("b"))
+	mustRegister(t, idx, makeTestTool("alpha", "ns", "Alpha", []string{"y"}), makeLocalBackend("a"))
+	mustRegister(t, idx, makeTestTool("gamma", "ns", "Gamma", nil), makeLocalBackend("g"))
+	if err := idx.SetHidden("ns:gamma", true); err != nil {
+		t.Fatalf("SetHidden failed: %v", err)
+	}
+	builds := idx.Stats().SearchDocBuilds
+
+	summaries := idx.SnapshotSummaries()
+	if len(summaries) != 2 || summaries[0].ID != "ns:alpha" || summaries[1].ID != "ns:beta" {
+		t.Fatalf("unexpected summaries: %+v", summaries)
+	}
+	if idx.Stats().SearchDocBuilds != builds {
+		t.Error("SnapshotSummaries must not rebuild search docs")
+	}
+
+	summaries[0].Tags[0] = "mutated"
+	if again := idx.SnapshotSummaries(); again[0].Tags[0] != "y" {
+		t.Fatalf("snapshot mutation leaked into index: %+v", again)
+	}
+}
+
+func TestOnDocsRebuilt_CalledPerRebuild(t *testing.T) {
+	var calls []uint64
+	var lastDocs []SearchDoc
+	idx := NewInMemoryIndex(IndexOptions{
+		OnDocsRebuilt: func(docs []SearchDoc, version uint64) {
+			calls = append(calls, version)
+			lastDocs = docs
+			for _, doc := range docs {
+				for i := range doc.Tokens {
+					doc.Tokens[i] = "mutated"
+				}
+			}
+		},
+	})
+
+	mustRegister(t, idx, makeTestTool("alpha", "ns", "Alpha", nil), makeLocalBackend("a"))
+	mustRegister(t, idx, makeTestTool("beta", "ns", "Beta", nil), makeLocalBackend("b"))
+	if len(calls) != 0 {
+		t.Fatalf("expected no rebuild before search, got %d", len(calls))
+	}
+
+	_, _ = idx.Search("", 10)
+	_, _ = idx.Search("alpha", 10) // cached, no rebuild
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 rebuild notification, got %d", len(calls))
+	}
+	if len(lastDocs) != 2 {
+		t.Fatalf("expected 2 docs, got %d", len(lastDocs))
+	}
+	if docs := idx.SearchDocsSnapshot(); docs[0].Tokens[0] == "mutated" {
+		t.Fatalf("hook mutation leaked into index tokens: %+v", docs[0].Tokens)
+	}
+
+	version := idx.Refresh()
+	if len(calls) != 2 || calls[1] != version {
+		t.Fatalf("expected refresh notification at version %d, got %v", version, calls)
+	}
+}
+
+func TestOnDocsRebuilt_NotCalledForBackendOnlyChanges(t *testing.T) {
+	calls := 0
+	idx := NewInMemoryIndex(IndexOptions{
+		OnDocsRebuilt: func([]SearchDoc, uint64) { calls++ },
+	})
+	tool := makeTestTool("alpha", "ns", "Alpha", nil)
+	mustRegister(t, idx, tool, makeLocalBackend("a"))
+	_, _ = idx.Search("", 10)
+	if calls != 1 {
+		t.Fatalf("expected 1 rebuild notification, got %d", calls)
+	}
+
+	before := idx.Stats().Version
+	mustRegister(t, idx, tool, makeMCPBackend("server"))
+	if err := idx.UnregisterBackend("ns:alpha", toolmodel.BackendKindMCP, "server"); err != nil {
+		t.Fatalf("UnregisterBackend failed: %v", err)
+	}
+	if results, _ := idx.Search("alpha", 10); len(results) != 1 {
+		t.Fatalf("expected tool to stay searchable, got %+v", results)
+	}
+	if calls != 1 {
+		t.Fatalf("backend-only mutations must not rebuild docs, got %d notifications", calls)
+	}
+	if idx.Stats().Version == before {
+		t.Fatal("expected backend-only mutations to advance the version")
+	}
+
+	// A doc change still rebuilds and notifies.
+	mustRegister(t, idx, makeTestTool("alpha", "ns", "Alpha", []string{"new"}), makeLocalBackend("a"))
+	_, _ = idx.Search("", 10)
+	if calls != 2 {
+		t.Fatalf("expected a rebuild after a tag change, got %d notifications", calls)
+	}
+}
+
 // ============================================================
 // Tests for Cursor Pagination
 // ============================================================
@@ -1750,3 +3826,32 @@ func TestListNamespacesPage_PaginatesWithCursor(t *testing.T) {
 		t.Fatalf("expected ns3, got %q", nextNamespaces[0])
 	}
 }
+
+type pagedMockSearcher struct {
+	mockSearcher
+	gotCursor string
+	gotLimit  int
+}
+
+func (p *pagedMockSearcher) SearchPage(_ string, limit int, cursor string, docs []SearchDoc) ([]Summary, string, error) {
+	p.gotCursor = cursor
+	p.gotLimit = limit
+	return []Summary{docs[0].Summary}, "native-next", nil
+}
+
+func TestSearchPage_DelegatesToPagedSearcher(t *testing.T) {
+	searcher := &pagedMockSearcher{}
+	idx := NewInMemoryIndex(IndexOptions{Searcher: searcher})
+	mustRegister(t, idx, makeTestTool("alpha", "ns", "alpha tool", nil), makeLocalBackend("alpha"))
+
+	results, next, err := idx.SearchPage("alpha", 5, "native-cursor")
+	if err != nil {
+		t.Fatalf("SearchPage failed: %v", err)
+	}
+	if len(results) != 1 || next != "native-next" {
+		t.Fatalf("unexpected page: %v, %q", results, next)
+	}
+	if searcher.gotCursor != "native-cursor" || searcher.gotLimit != 5 {
+		t.Fatalf("searcher received cursor=%q limit=%d", searcher.gotCursor, searcher.gotLimit)
+	}
+}
@@ -0,0 +1,349 @@
+package toolindex
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Field weights for NewInvertedSearcher, on the default searcher's scale.
+const (
+	invertedNameWeight      = 100
+	invertedNamespaceWeight = 50
+	invertedTextWeight      = 10
+)
+
+// NewInvertedSearcher returns a Searcher for large registries that answers
+// queries from an inverted token index instead of scanning every doc.
+//
+// The query is split with Tokenize, and a tool matches when every query
+// token is a prefix of one of its SearchDoc.Tokens, so "git pul" finds
+// "git:pull". Each query token scores by the best field it matched (name,
+// then namespace, then any other text); results rank by total score, then
+// ID. An empty query returns docs in input order up to limit.
+//
+// The index is built lazily from the docs passed to Search and reused for as
+// long as later calls pass the same docs or a subset of them, such as the
+// filtered slices InMemoryIndex hands out; any other change rebuilds it.
+// Through InMemoryIndex the snapshot version is known, so the mapping from a
+// filtered slice to the index is cached per version and reused by later
+// queries with the same filter. SetSearcher prebuilds the index; call Warmup
+// after bulk registration to keep the rebuild off the first query. The
+// searcher is safe for concurrent use and implements VersionedSearcher,
+// PrebuildSearcher, and DeterministicSearcher.
+func NewInvertedSearcher() Searcher {
+	return &invertedSearcher{}
+}
+
+type invertedSearcher struct {
+	mu    sync.Mutex
+	index *invertedIndex
+	// version is the snapshot version index is known to match, valid when
+	// versioned is set; subsets caches visibility maps seen at that version.
+	version   uint64
+	versioned bool
+	subsets   []cachedSubset
+}
+
+// maxCachedSubsets bounds the visibility maps kept per version, one per
+// distinct filter in use.
+const maxCachedSubsets = 4
+
+// cachedSubset is the visibility map for one filtered docs slice.
+type cachedSubset struct {
+	ids     []string // doc IDs in order, to recognize the same slice again
+	visible []int
+}
+
+// invertedIndex is an immutable token index over one docs slice.
+type invertedIndex struct {
+	docs     []SearchDoc
+	byID     map[string]int
+	terms    []string // sorted vocabulary
+	postings map[string][]posting
+}
+
+// posting records that a doc contains a term, with the best field weight the
+// term appears in for that doc.
+type posting struct {
+	doc    int
+	weight int
+}
+
+// Search implements Searcher.
+func (s *invertedSearcher) Search(query string, limit int, docs []SearchDoc) ([]Summary, error) {
+	return s.search(query, limit, docs, func() (*invertedIndex, []int) {
+		return s.indexFor(docs)
+	})
+}
+
+// SearchVersioned implements VersionedSearcher.
+func (s *invertedSearcher) SearchVersioned(query string, limit int, docs []SearchDoc, version uint64) ([]Summary, error) {
+	return s.search(query, limit, docs, func() (*invertedIndex, []int) {
+		return s.indexForVersion(docs, version)
+	})
+}
+
+// search runs query against the index returned by lookup, which is only
+// called for non-empty queries.
+func (s *invertedSearcher) search(query string, limit int, docs []SearchDoc, lookup func() (*invertedIndex, []int)) ([]Summary, error) {
+	if limit <= 0 {
+		return []Summary{}, nil
+	}
+	queryTokens := Tokenize(normalizeSeparators(query))
+	if len(queryTokens) == 0 {
+		results := make([]Summary, 0, min(limit, len(docs)))
+		for _, doc := range docs[:min(limit, len(docs))] {
+			results = append(results, doc.Summary)
+		}
+		return results, nil
+	}
+
+	index, visible := lookup()
+	scores := index.match(queryTokens)
+
+	type hit struct {
+		summary Summary
+		score   int
+	}
+	hits := make([]hit, 0, len(scores))
+	for doc, score := range scores {
+		if visible == nil {
+			hits = append(hits, hit{summary: docs[doc].Summary, score: score})
+		} else if i := visible[doc]; i >= 0 {
+			hits = append(hits, hit{summary: docs[i].Summary, score: score})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].score == hits[j].score {
+			return hits[i].summary.ID < hits[j].summary.ID
+		}
+		return hits[i].score > hits[j].score
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	results := make([]Summary, len(hits))
+	for i, h := range hits {
+		results[i] = h.summary
+	}
+	return results, nil
+}
+
+// Prebuild implements PrebuildSearcher.
+func (s *invertedSearcher) Prebuild(docs []SearchDoc, version uint64) {
+	s.indexForVersion(docs, version)
+}
+
+// Deterministic implements DeterministicSearcher.
+func (s *invertedSearcher) Deterministic() bool {
+	return true
+}
+
+// indexFor returns an index covering docs, rebuilding it if necessary. When
+// docs is exactly the indexed slice, visible is nil and index positions are
+// docs positions; otherwise visible maps each index position to its position
+// in docs, or -1 for docs left out.
+func (s *invertedSearcher) indexFor(docs []SearchDoc) (index *invertedIndex, visible []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.versioned = false
+	s.subsets = nil
+	return s.resolveLocked(docs)
+}
+
+// indexForVersion is indexFor for docs taken from the snapshot at version.
+// Docs at one version are identical, so a slice with the same IDs as one
+// already seen at that version reuses its visibility map without lookups.
+func (s *invertedSearcher) indexForVersion(docs []SearchDoc, version uint64) (index *invertedIndex, visible []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.versioned || s.version != version {
+		s.versioned, s.version, s.subsets = true, version, nil
+	} else {
+		for _, c := range s.subsets {
+			if sameIDs(docs, c.ids) {
+				return s.index, c.visible
+			}
+		}
+	}
+
+	previous := s.index
+	index, visible = s.resolveLocked(docs)
+	if index != previous {
+		s.subsets = nil
+	}
+	if len(s.subsets) == maxCachedSubsets {
+		s.subsets = s.subsets[1:]
+	}
+	ids := make([]string, len(docs))
+	for i := range docs {
+		ids[i] = docs[i].Summary.ID
+	}
+	s.subsets = append(s.subsets, cachedSubset{ids: ids, visible: visible})
+	return index, visible
+}
+
+// resolveLocked reuses the current index for docs when it covers them and
+// rebuilds it otherwise.
+// Must be called with s.mu held.
+func (s *invertedSearcher) resolveLocked(docs []SearchDoc) (index *invertedIndex, visible []int) {
+	if s.index != nil {
+		if s.index.sameDocs(docs) {
+			return s.index, nil
+		}
+		if visible, ok := s.index.subset(docs); ok {
+			return s.index, visible
+		}
+	}
+	s.index = buildInvertedIndex(docs)
+	return s.index, nil
+}
+
+// sameIDs reports whether docs holds exactly ids, in order. The IDs share
+// their strings with the snapshot, so the comparisons are usually pointer
+// checks.
+func sameIDs(docs []SearchDoc, ids []string) bool {
+	if len(docs) != len(ids) {
+		return false
+	}
+	for i := range docs {
+		if docs[i].Summary.ID != ids[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sameDocs reports whether docs holds the indexed docs in the same order.
+// Snapshots share their strings with the index, so the comparisons are
+// usually pointer checks.
+func (x *invertedIndex) sameDocs(docs []SearchDoc) bool {
+	if len(docs) != len(x.docs) {
+		return false
+	}
+	for i := range docs {
+		if docs[i].Summary.ID != x.docs[i].Summary.ID || docs[i].DocText != x.docs[i].DocText {
+			return false
+		}
+	}
+	return true
+}
+
+// subset maps index positions to positions in docs, reporting false if docs
+// contains a tool that is not indexed or whose text has changed.
+func (x *invertedIndex) subset(docs []SearchDoc) ([]int, bool) {
+	if len(docs) > len(x.docs) {
+		return nil, false
+	}
+	visible := make([]int, len(x.docs))
+	for i := range visible {
+		visible[i] = -1
+	}
+	for i, doc := range docs {
+		pos, ok := x.byID[doc.Summary.ID]
+		if !ok || x.docs[pos].DocText != doc.DocText {
+			return nil, false
+		}
+		visible[pos] = i
+	}
+	return visible, true
+}
+
+func buildInvertedIndex(docs []SearchDoc) *invertedIndex {
+	x := &invertedIndex{
+		docs:     docs,
+		byID:     make(map[string]int, len(docs)),
+		postings: make(map[string][]posting),
+	}
+	for i, doc := range docs {
+		x.byID[doc.Summary.ID] = i
+
+		weights := make(map[string]int)
+		tokens := doc.Tokens
+		if tokens == nil {
+			tokens = Tokenize(doc.DocText)
+		}
+		for _, token := range tokens {
+			weights[token] = invertedTextWeight
+		}
+		nameTokens, namespaceTokens := docFieldTerms(doc)
+		for _, token := range namespaceTokens {
+			weights[token] = invertedNamespaceWeight
+		}
+		for _, token := range nameTokens {
+			weights[token] = invertedNameWeight
+		}
+		for token, weight := range weights {
+			x.postings[token] = append(x.postings[token], posting{doc: i, weight: weight})
+		}
+	}
+
+	x.terms = make([]string, 0, len(x.postings))
+	for term := range x.postings {
+		x.terms = append(x.terms, term)
+	}
+	slices.Sort(x.terms)
+	return x
+}
+
+// docFieldTerms returns the name and namespace tokens of doc, preferring the
+// per-field tokens when the index was built with WeightedSearchDocs.
+func docFieldTerms(doc SearchDoc) (name, namespace []string) {
+	if doc.Fields != nil {
+		for token := range doc.Fields.Name {
+			name = append(name, token)
+		}
+		for token := range doc.Fields.Namespace {
+			namespace = append(namespace, token)
+		}
+		return name, namespace
+	}
+	return Tokenize(normalizeSeparators(doc.Summary.Name)), Tokenize(normalizeSeparators(doc.Summary.Namespace))
+}
+
+// match returns the total score of each index position that matches every
+// query token, intersecting the candidate sets token by token.
+func (x *invertedIndex) match(queryTokens []string) map[int]int {
+	var scores map[int]int
+	for _, token := range queryTokens {
+		best := x.prefixWeights(token)
+		if scores == nil {
+			scores = best
+		} else {
+			for doc, score := range scores {
+				if weight, ok := best[doc]; ok {
+					scores[doc] = score + weight
+				} else {
+					delete(scores, doc)
+				}
+			}
+		}
+		if len(scores) == 0 {
+			return nil
+		}
+	}
+	return scores
+}
+
+// prefixWeights returns, for each doc with a term starting with prefix, the
+// highest weight among those terms.
+func (x *invertedIndex) prefixWeights(prefix string) map[int]int {
+	best := make(map[int]int)
+	start := sort.SearchStrings(x.terms, prefix)
+	for _, term := range x.terms[start:] {
+		if !strings.HasPrefix(term, prefix) {
+			break
+		}
+		for _, p := range x.postings[term] {
+			if p.weight > best[p.doc] {
+				best[p.doc] = p.weight
+			}
+		}
+	}
+	return best
+}
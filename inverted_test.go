@@ -0,0 +1,161 @@
+package toolindex
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestInvertedSearcher(t *testing.T) {
+	idx := NewInMemoryIndex(IndexOptions{Searcher: NewInvertedSearcher()})
+	mustRegister(t, idx, makeTestTool("pull", "git", "Fetch and merge", nil), makeLocalBackend("a"))
+	mustRegister(t, idx, makeTestTool("push", "git", "Upload commits", []string{"remote"}), makeLocalBackend("b"))
+	mustRegister(t, idx, makeTestTool("sync", "drive", "Pull files from git-lfs", nil), makeLocalBackend("c"))
+
+	// Name matches outrank description matches; every token must match.
+	results, err := idx.Search("Git Pul", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if got, want := summaryIDs(results), []string{"git:pull", "drive:sync"}; !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if results, _ := idx.Search("git remote", 10); len(results) != 1 || results[0].ID != "git:push" {
+		t.Fatalf("expected tag match, got %+v", results)
+	}
+	if results, _ := idx.Search("git", 1); len(results) != 1 || results[0].ID != "git:pull" {
+		t.Fatalf("expected limit to apply, got %+v", results)
+	}
+	if results, _ := idx.Search("", 10); len(results) != 3 {
+		t.Fatalf("expected all docs for empty query, got %+v", results)
+	}
+	if _, _, err := idx.SearchPage("git", 1, ""); err != nil {
+		t.Fatalf("expected inverted searcher to satisfy the determinism check: %v", err)
+	}
+}
+
+func TestInvertedSearcher_TracksChanges(t *testing.T) {
+	idx := NewInMemoryIndex(IndexOptions{Searcher: NewInvertedSearcher()})
+	mustRegister(t, idx, makeTestTool("pull", "git", "Fetch and merge", nil), makeLocalBackend("a"))
+	if results, _ := idx.Search("rebase", 10); len(results) != 0 {
+		t.Fatalf("expected no match yet, got %+v", results)
+	}
+
+	mustRegister(t, idx, makeTestTool("rebase", "git", "Replay commits", nil), makeLocalBackend("b"))
+	if results, _ := idx.Search("rebase", 10); len(results) != 1 {
+		t.Fatalf("expected new tool to be indexed, got %+v", results)
+	}
+
+	// Hidden tools are filtered out of the docs, reusing the index.
+	if err := idx.SetHidden("git:rebase", true); err != nil {
+		t.Fatalf("SetHidden failed: %v", err)
+	}
+	if results, _ := idx.Search("rebase", 10); len(results) != 0 {
+		t.Fatalf("expected hidden tool to be excluded, got %+v", results)
+	}
+	results, _ := idx.SearchFiltered("rebase", 10, SearchFilter{IncludeHidden: true})
+	if len(results) != 1 {
+		t.Fatalf("expected hidden tool with IncludeHidden, got %+v", results)
+	}
+
+	if err := idx.SetCategory("git:pull", "history"); err != nil {
+		t.Fatalf("SetCategory failed: %v", err)
+	}
+	if results, _ := idx.Search("fetch history", 10); len(results) != 1 || results[0].ID != "git:pull" {
+		t.Fatalf("expected changed text to be reindexed, got %+v", results)
+	}
+}
+
+func TestInvertedSearcher_CachesFilteredSubsetsPerVersion(t *testing.T) {
+	searcher := NewInvertedSearcher()
+	idx := NewInMemoryIndex(IndexOptions{Searcher: searcher})
+	mustRegister(t, idx, makeTestTool("zeta", "git", "Zeta commits", nil), makeLocalBackend("z"))
+	mustRegister(t, idx, makeTestTool("alpha", "git", "Alpha commits", nil), makeLocalBackend("a"))
+	mustRegister(t, idx, makeTestTool("hidden", "git", "Hidden commits", nil), makeLocalBackend("h"))
+	if err := idx.SetHidden("git:hidden", true); err != nil {
+		t.Fatalf("SetHidden failed: %v", err)
+	}
+	idx.Warmup() // indexes every doc, hidden ones included
+
+	for i := 0; i < 2; i++ {
+		results, _ := idx.Search("commits", 10)
+		if got, want := summaryIDs(results), []string{"git:alpha", "git:zeta"}; !slices.Equal(got, want) {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+	// Same version, same length, different order: must not reuse the map.
+	results, _ := idx.SearchFiltered("commits", 10, SearchFilter{RegistrationOrder: true})
+	if got, want := summaryIDs(results), []string{"git:alpha", "git:zeta"}; !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	results, _ = idx.SearchFiltered("commits", 10, SearchFilter{IncludeHidden: true})
+	if len(results) != 3 {
+		t.Fatalf("expected hidden tool with IncludeHidden, got %+v", results)
+	}
+
+	inv := searcher.(*invertedSearcher)
+	inv.mu.Lock()
+	cached := len(inv.subsets)
+	inv.mu.Unlock()
+	if cached != 3 {
+		t.Fatalf("expected one cached visibility map per distinct slice, got %d", cached)
+	}
+
+	// A new version drops the cache.
+	mustRegister(t, idx, makeTestTool("beta", "git", "Beta commits", nil), makeLocalBackend("b"))
+	if results, _ := idx.Search("commits", 10); len(results) != 3 {
+		t.Fatalf("expected new tool after a version change, got %+v", results)
+	}
+}
+
+func BenchmarkSearch_InvertedThroughIndex(b *testing.B) {
+	idx := NewInMemoryIndex(IndexOptions{Searcher: NewInvertedSearcher()})
+	for i := 0; i < 50000; i++ {
+		tool := makeTestTool(fmt.Sprintf("tool_%d", i), fmt.Sprintf("ns%d", i%500), fmt.Sprintf("Performs operation %d on records", i), []string{fmt.Sprintf("group%d", i%100)})
+		if err := idx.RegisterTool(tool, makeLocalBackend(fmt.Sprintf("b%d", i))); err != nil {
+			b.Fatalf("RegisterTool failed: %v", err)
+		}
+	}
+	// Hiding a tool makes every default Search pass a filtered subset.
+	if err := idx.SetHidden("ns0:tool_0", true); err != nil {
+		b.Fatalf("SetHidden failed: %v", err)
+	}
+	idx.Warmup()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := idx.Search("group42", 20); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSearch_InvertedVsLexical(b *testing.B) {
+	idx := NewInMemoryIndex()
+	for i := 0; i < 50000; i++ {
+		tool := makeTestTool(fmt.Sprintf("tool_%d", i), fmt.Sprintf("ns%d", i%500), fmt.Sprintf("Performs operation %d on records", i), []string{fmt.Sprintf("group%d", i%100)})
+		if err := idx.RegisterTool(tool, makeLocalBackend(fmt.Sprintf("b%d", i))); err != nil {
+			b.Fatalf("RegisterTool failed: %v", err)
+		}
+	}
+	docs := idx.SearchDocsSnapshot()
+
+	searchers := map[string]Searcher{
+		"lexical":  idx.DefaultSearcher(),
+		"inverted": NewInvertedSearcher(),
+	}
+	for _, name := range []string{"lexical", "inverted"} {
+		searcher := searchers[name]
+		if ps, ok := searcher.(PrebuildSearcher); ok {
+			ps.Prebuild(docs, 0)
+		}
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := searcher.Search("group42", 20, docs); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
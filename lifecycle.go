@@ -0,0 +1,76 @@
+package toolindex
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// Touch records that a tool was confirmed present without changing it.
+// It refreshes the tool's last-seen time only: the index version is not
+// bumped, search docs are not invalidated, and listeners receive a
+// ChangeRefreshed event scoped to the tool rather than a content change.
+func (idx *InMemoryIndex) Touch(toolID string) error {
+	idx.mu.Lock()
+	record, exists := idx.tools[toolID]
+	if !exists {
+		idx.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrNotFound, toolID)
+	}
+	record.lastSeen = idx.now()
+	version := idx.indexVersion
+	listeners := idx.snapshotListenersLocked()
+	idx.mu.Unlock()
+
+	notifyListeners(listeners, ChangeEvent{Type: ChangeRefreshed, ToolID: toolID, Version: version})
+	return nil
+}
+
+// LastSeen returns when a tool was last registered or touched.
+func (idx *InMemoryIndex) LastSeen(toolID string) (time.Time, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	record, exists := idx.lookupLocked(toolID)
+	if !exists {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrNotFound, toolID)
+	}
+	return record.lastSeen, nil
+}
+
+// RecentlyChanged returns up to limit visible tools ordered by when they were
+// last registered or changed, newest first, with ties broken by ID.
+// Re-registration, backend additions and removals, tag updates, deprecation,
+// and visibility changes all count as changes; Touch does not.
+func (idx *InMemoryIndex) RecentlyChanged(limit int) ([]Summary, error) {
+	if limit <= 0 {
+		return []Summary{}, nil
+	}
+
+	idx.mu.RLock()
+	records := make([]*toolRecord, 0, len(idx.tools))
+	for _, record := range idx.tools {
+		if !record.hidden {
+			records = append(records, record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if !a.updatedAt.Equal(b.updatedAt) {
+			return a.updatedAt.After(b.updatedAt)
+		}
+		return a.summary.ID < b.summary.ID
+	})
+	if len(records) > limit {
+		records = records[:limit]
+	}
+	results := make([]Summary, len(records))
+	for i, record := range records {
+		results[i] = record.summary
+		results[i].Tags = slices.Clone(results[i].Tags)
+	}
+	idx.mu.RUnlock()
+
+	return results, nil
+}
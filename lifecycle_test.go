@@ -0,0 +1,110 @@
+package toolindex
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTouch_RefreshesLastSeenOnly(t *testing.T) {
+	idx := NewInMemoryIndex()
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	idx.now = func() time.Time { return clock }
+
+	mustRegister(t, idx, makeTestTool("ping", "net", "Ping a host", nil), makeMCPBackend("net"))
+	if _, err := idx.Search("ping", 10); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	before := idx.Stats()
+
+	var events []ChangeEvent
+	idx.OnChange(func(e ChangeEvent) { events = append(events, e) })
+
+	clock = clock.Add(time.Minute)
+	if err := idx.Touch("net:ping"); err != nil {
+		t.Fatalf("Touch failed: %v", err)
+	}
+
+	seen, err := idx.LastSeen("net:ping")
+	if err != nil {
+		t.Fatalf("LastSeen failed: %v", err)
+	}
+	if !seen.Equal(clock) {
+		t.Errorf("expected last-seen %v, got %v", clock, seen)
+	}
+
+	if _, err := idx.Search("ping", 10); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	after := idx.Stats()
+	if after.Version != before.Version || after.SearchDocBuilds != before.SearchDocBuilds {
+		t.Errorf("Touch changed version or rebuilt docs: before %+v, after %+v", before, after)
+	}
+
+	if len(events) != 1 || events[0].Type != ChangeRefreshed || events[0].ToolID != "net:ping" || events[0].Version != before.Version {
+		t.Errorf("unexpected events: %+v", events)
+	}
+
+	if err := idx.Touch("net:missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRecentlyChanged(t *testing.T) {
+	idx := NewInMemoryIndex()
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	idx.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+
+	mustRegister(t, idx, makeTestTool("a", "ns", "A", nil), makeLocalBackend("a"))
+	mustRegister(t, idx, makeTestTool("b", "ns", "B", nil), makeLocalBackend("b"))
+	mustRegister(t, idx, makeTestTool("c", "ns", "C", nil), makeLocalBackend("c"))
+
+	assertOrder := func(want ...string) {
+		t.Helper()
+		got, err := idx.RecentlyChanged(len(want))
+		if err != nil {
+			t.Fatalf("RecentlyChanged failed: %v", err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("expected %d results, got %+v", len(want), got)
+		}
+		for i := range want {
+			if got[i].ID != want[i] {
+				t.Fatalf("position %d: expected %s, got %s", i, want[i], got[i].ID)
+			}
+		}
+	}
+	assertOrder("ns:c", "ns:b", "ns:a")
+
+	// A backend addition counts as a change.
+	mustRegister(t, idx, makeTestTool("a", "ns", "A", nil), makeMCPBackend("a"))
+	assertOrder("ns:a", "ns:c", "ns:b")
+
+	// So does a tag update.
+	if _, err := idx.TagNamespace("ns", "core"); err != nil {
+		t.Fatalf("TagNamespace failed: %v", err)
+	}
+	assertOrder("ns:c", "ns:b", "ns:a")
+
+	// Returned tags are copies.
+	changed, _ := idx.RecentlyChanged(1)
+	changed[0].Tags[0] = "mutated"
+	if summary, _ := idx.GetSummary("ns:c"); summary.Tags[0] != "core" {
+		t.Fatalf("RecentlyChanged exposed internal tags: %v", summary.Tags)
+	}
+
+	// Touch does not.
+	if err := idx.Touch("ns:a"); err != nil {
+		t.Fatalf("Touch failed: %v", err)
+	}
+	assertOrder("ns:c")
+
+	// Ties are broken by ID.
+	idx.now = func() time.Time { return clock }
+	mustRegister(t, idx, makeTestTool("z", "ns", "Z", nil), makeLocalBackend("z"))
+	mustRegister(t, idx, makeTestTool("y", "ns", "Y", nil), makeLocalBackend("y"))
+	assertOrder("ns:y", "ns:z")
+}
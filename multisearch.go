@@ -0,0 +1,126 @@
+package toolindex
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MergeStrategy controls how MultiSearcher combines one tool's scores from
+// several sub-searchers.
+type MergeStrategy int
+
+const (
+	// MergeMax keeps the highest score any sub-searcher gave a tool.
+	MergeMax MergeStrategy = iota
+	// MergeSum adds the scores from every sub-searcher that matched a tool,
+	// favoring tools that several strategies agree on.
+	MergeSum
+)
+
+// MultiSearcher returns a Searcher that queries each of searchers and merges
+// their results by tool ID using merge, ranking by merged score, then ID.
+//
+// Sub-searchers that implement Explainer contribute their scores; for the
+// rest, a result's score is its reverse rank (the last of n results scores 1,
+// the first n), so scales only compare meaningfully between searchers of the
+// same kind. The returned searcher implements Explainer, with one component
+// per contributing sub-searcher, and DeterministicSearcher, reporting true
+// only when every sub-searcher does.
+func MultiSearcher(searchers []Searcher, merge MergeStrategy) Searcher {
+	return &multiSearcher{searchers: append([]Searcher(nil), searchers...), merge: merge}
+}
+
+type multiSearcher struct {
+	searchers []Searcher
+	merge     MergeStrategy
+}
+
+// Search implements Searcher.
+func (m *multiSearcher) Search(query string, limit int, docs []SearchDoc) ([]Summary, error) {
+	explanations, err := m.Explain(query, limit, docs)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]Summary, len(explanations))
+	for i, e := range explanations {
+		results[i] = e.Summary
+	}
+	return results, nil
+}
+
+// Explain implements Explainer.
+func (m *multiSearcher) Explain(query string, limit int, docs []SearchDoc) ([]Explanation, error) {
+	if limit <= 0 {
+		return []Explanation{}, nil
+	}
+	// A tool's max score is always within some sub-searcher's top limit, but
+	// a sum may be built from results below it, so MergeSum asks for all.
+	subLimit := limit
+	if m.merge == MergeSum {
+		subLimit = len(docs)
+	}
+
+	merged := make(map[string]*Explanation)
+	for i, s := range m.searchers {
+		scored, err := explainOrRank(s, query, subLimit, docs)
+		if err != nil {
+			return nil, fmt.Errorf("searcher %d: %w", i, err)
+		}
+		reason := fmt.Sprintf("searcher %d", i)
+		for _, e := range scored {
+			entry, ok := merged[e.Summary.ID]
+			if !ok {
+				entry = &Explanation{Summary: e.Summary, Score: e.Score}
+				merged[e.Summary.ID] = entry
+			} else if m.merge == MergeSum {
+				entry.Score += e.Score
+			} else if e.Score > entry.Score {
+				entry.Score = e.Score
+			}
+			entry.Components = append(entry.Components, ScoreComponent{Reason: reason, Points: e.Score})
+		}
+	}
+
+	results := make([]Explanation, 0, len(merged))
+	for _, e := range merged {
+		results = append(results, *e)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score == results[j].Score {
+			return results[i].Summary.ID < results[j].Summary.ID
+		}
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// Deterministic implements DeterministicSearcher.
+func (m *multiSearcher) Deterministic() bool {
+	for _, s := range m.searchers {
+		ds, ok := s.(DeterministicSearcher)
+		if !ok || !ds.Deterministic() {
+			return false
+		}
+	}
+	return true
+}
+
+// explainOrRank returns s's scored results, using Explain when s implements
+// Explainer and reverse rank otherwise.
+func explainOrRank(s Searcher, query string, limit int, docs []SearchDoc) ([]Explanation, error) {
+	if ex, ok := s.(Explainer); ok {
+		return ex.Explain(query, limit, docs)
+	}
+	results, err := s.Search(query, limit, docs)
+	if err != nil {
+		return nil, err
+	}
+	scored := make([]Explanation, len(results))
+	for i, r := range results {
+		scored[i] = Explanation{Summary: r, Score: len(results) - i}
+	}
+	return scored, nil
+}
@@ -0,0 +1,86 @@
+package toolindex
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// tagExactSearcher matches docs carrying the query as an exact tag.
+type tagExactSearcher struct{}
+
+func (tagExactSearcher) Search(query string, limit int, docs []SearchDoc) ([]Summary, error) {
+	var out []Summary
+	for _, doc := range docs {
+		if len(out) < limit && slices.Contains(doc.Summary.Tags, query) {
+			out = append(out, doc.Summary)
+		}
+	}
+	return out, nil
+}
+
+func summaryIDs(results []Summary) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func TestMultiSearcher_MergesAndDedupes(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("deploy", "ci", "Ship a build", nil), makeLocalBackend("d"))
+	mustRegister(t, idx, makeTestTool("rollout", "ci", "Gradual release", []string{"deploy"}), makeLocalBackend("r"))
+	mustRegister(t, idx, makeTestTool("lint", "ci", "Lint code", nil), makeLocalBackend("l"))
+
+	idx.SetSearcher(MultiSearcher([]Searcher{idx.DefaultSearcher(), tagExactSearcher{}}, MergeMax))
+	results, err := idx.Search("deploy", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	// ci:deploy scores 150 lexically; ci:rollout matches lexically via its
+	// tag (10, plus 25 for the tag prefix) and as the tag searcher's only
+	// result (1).
+	if got, want := summaryIDs(results), []string{"ci:deploy", "ci:rollout"}; !slices.Equal(got, want) {
+		t.Fatalf("expected merged results %v, got %v", want, got)
+	}
+
+	explanations, err := idx.SearchExplain("deploy", 10)
+	if err != nil {
+		t.Fatalf("SearchExplain failed: %v", err)
+	}
+	if explanations[1].Score != 35 || len(explanations[1].Components) != 2 {
+		t.Fatalf("expected max score 35 from two searchers, got %+v", explanations[1])
+	}
+
+	idx.SetSearcher(MultiSearcher([]Searcher{idx.DefaultSearcher(), tagExactSearcher{}}, MergeSum))
+	explanations, _ = idx.SearchExplain("deploy", 10)
+	if explanations[1].Summary.ID != "ci:rollout" || explanations[1].Score != 36 {
+		t.Fatalf("expected summed score 36 for ci:rollout, got %+v", explanations[1])
+	}
+
+	if results, _ := idx.Search("deploy", 1); len(results) != 1 || results[0].ID != "ci:deploy" {
+		t.Fatalf("expected limit to apply after merging, got %+v", results)
+	}
+}
+
+func TestMultiSearcher_ErrorsAndDeterminism(t *testing.T) {
+	failing := &mockSearcher{searchFunc: func(string, int, []SearchDoc) ([]Summary, error) {
+		return nil, errors.New("boom")
+	}}
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("a", "ns", "A", nil), makeLocalBackend("a"))
+
+	multi := MultiSearcher([]Searcher{idx.DefaultSearcher(), failing}, MergeMax)
+	idx.SetSearcher(multi)
+	if _, err := idx.Search("a", 10); err == nil {
+		t.Fatal("expected a sub-searcher error to fail the search")
+	}
+
+	if multi.(DeterministicSearcher).Deterministic() {
+		t.Error("expected non-deterministic when a sub-searcher does not report determinism")
+	}
+	if !MultiSearcher([]Searcher{idx.DefaultSearcher()}, MergeMax).(DeterministicSearcher).Deterministic() {
+		t.Error("expected deterministic when every sub-searcher is")
+	}
+}
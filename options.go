@@ -0,0 +1,92 @@
+package toolindex
+
+import "slices"
+
+// Option configures NewInMemoryIndex. IndexOptions is itself an Option that
+// sets every field; the With functions each set a single field and compose
+// in order.
+type Option interface {
+	applyOption(cfg *IndexOptions)
+}
+
+// applyOption implements Option by replacing the whole configuration.
+func (o IndexOptions) applyOption(cfg *IndexOptions) {
+	*cfg = o
+}
+
+// optionFunc adapts a function to Option.
+type optionFunc func(cfg *IndexOptions)
+
+func (f optionFunc) applyOption(cfg *IndexOptions) {
+	f(cfg)
+}
+
+// buildOptions folds opts into one IndexOptions, panicking if an
+// IndexOptions struct appears anywhere but first, where it would silently
+// overwrite the options before it.
+func buildOptions(opts []Option) IndexOptions {
+	var cfg IndexOptions
+	for i, opt := range opts {
+		if _, ok := opt.(IndexOptions); ok && i > 0 {
+			panic("toolindex: NewInMemoryIndex accepts at most one IndexOptions, as the first option")
+		}
+		if opt != nil {
+			opt.applyOption(&cfg)
+		}
+	}
+	return cfg
+}
+
+// WithSearcher sets IndexOptions.Searcher.
+func WithSearcher(s Searcher) Option {
+	return optionFunc(func(cfg *IndexOptions) { cfg.Searcher = s })
+}
+
+// WithBackendSelector sets IndexOptions.BackendSelector.
+func WithBackendSelector(sel BackendSelector) Option {
+	return optionFunc(func(cfg *IndexOptions) { cfg.BackendSelector = sel })
+}
+
+// WithRequireDeterministicSearcher sets
+// IndexOptions.RequireDeterministicSearcher.
+func WithRequireDeterministicSearcher(require bool) Option {
+	return optionFunc(func(cfg *IndexOptions) { cfg.RequireDeterministicSearcher = &require })
+}
+
+// WithUpstreamLoader sets IndexOptions.UpstreamLoader.
+func WithUpstreamLoader(loader UpstreamLoader) Option {
+	return optionFunc(func(cfg *IndexOptions) { cfg.UpstreamLoader = loader })
+}
+
+// WithSearcherFallback sets IndexOptions.SearcherFallback and
+// IndexOptions.OnSearcherError. onError may be nil.
+func WithSearcherFallback(fallback Searcher, onError func(err error)) Option {
+	return optionFunc(func(cfg *IndexOptions) {
+		cfg.SearcherFallback = fallback
+		cfg.OnSearcherError = onError
+	})
+}
+
+// WithMaxSearchLimit sets IndexOptions.MaxSearchLimit.
+func WithMaxSearchLimit(limit int) Option {
+	return optionFunc(func(cfg *IndexOptions) { cfg.MaxSearchLimit = limit })
+}
+
+// WithPinnedTools appends to IndexOptions.PinnedTools.
+func WithPinnedTools(ids ...string) Option {
+	return optionFunc(func(cfg *IndexOptions) { cfg.PinnedTools = append(slices.Clip(cfg.PinnedTools), ids...) })
+}
+
+// WithDecisionHook sets IndexOptions.OnDecision.
+func WithDecisionHook(hook func(DecisionEvent)) Option {
+	return optionFunc(func(cfg *IndexOptions) { cfg.OnDecision = hook })
+}
+
+// WithMaxTagsPerTool sets IndexOptions.MaxTagsPerTool and
+// IndexOptions.OnTagsTruncated. onTruncated may be nil.
+func WithMaxTagsPerTool(limit int, onTruncated func(toolID string, dropped []string)) Option {
+	return optionFunc(func(cfg *IndexOptions) {
+		cfg.MaxTagsPerTool = limit
+		cfg.OnTagsTruncated = onTruncated
+	})
+}
@@ -0,0 +1,60 @@
+package toolindex
+
+import (
+	"testing"
+
+	"github.com/jonwraymond/toolmodel"
+)
+
+func TestFunctionalOptions_Compose(t *testing.T) {
+	custom := &mockSearcher{searchFunc: func(string, int, []SearchDoc) ([]Summary, error) {
+		return []Summary{{ID: "custom"}}, nil
+	}}
+	mcpFirst := PriorityBackendSelector([]toolmodel.BackendKind{toolmodel.BackendKindMCP, toolmodel.BackendKindLocal})
+
+	idx := NewInMemoryIndex(
+		IndexOptions{MaxSearchLimit: 5},
+		WithSearcher(custom),
+		WithBackendSelector(mcpFirst),
+		WithRequireDeterministicSearcher(false),
+	)
+	mustRegister(t, idx, makeTestTool("t", "ns", "T", nil), makeLocalBackend("l"))
+	mustRegister(t, idx, makeTestTool("t", "ns", "T", nil), makeMCPBackend("m"))
+
+	if results, _ := idx.Search("t", 10); len(results) != 1 || results[0].ID != "custom" {
+		t.Fatalf("expected WithSearcher to apply, got %+v", results)
+	}
+	if _, backend, _ := idx.GetTool("ns:t"); backend.Kind != toolmodel.BackendKindMCP {
+		t.Errorf("expected WithBackendSelector to apply, got %s", backend.Kind)
+	}
+	if idx.maxSearchLimit != 5 || idx.requireDeterministicSearcher {
+		t.Errorf("expected struct and functional options to combine, got limit %d require %v", idx.maxSearchLimit, idx.requireDeterministicSearcher)
+	}
+
+	idx = NewInMemoryIndex(WithPinnedTools("ns:a"), WithPinnedTools("ns:b"))
+	if len(idx.lexical.pinned) != 2 {
+		t.Errorf("expected pinned tools to accumulate, got %v", idx.lexical.pinned)
+	}
+}
+
+func TestNewInMemoryIndex_RejectsMisplacedStructs(t *testing.T) {
+	tests := map[string][]Option{
+		"two structs":            {IndexOptions{MaxSearchLimit: 1}, IndexOptions{MaxSearchLimit: 2}},
+		"struct after With func": {WithMaxSearchLimit(1), IndexOptions{}},
+	}
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected NewInMemoryIndex to panic")
+				}
+			}()
+			NewInMemoryIndex(opts...)
+		})
+	}
+
+	// A single struct still applies in full.
+	if idx := NewInMemoryIndex(IndexOptions{MaxSearchLimit: 3}); idx.maxSearchLimit != 3 {
+		t.Errorf("expected single struct to apply, got limit %d", idx.maxSearchLimit)
+	}
+}
@@ -0,0 +1,423 @@
+package toolindex
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/jonwraymond/toolmodel"
+)
+
+// SearchFilter constrains which tools are eligible for a search.
+// Filters are applied to the search docs before the searcher runs, so they
+// work with any Searcher implementation. The zero value matches every tool.
+type SearchFilter struct {
+	// ExcludeDeprecated omits tools marked deprecated via Deprecate.
+	ExcludeDeprecated bool
+	// IncludeHidden includes tools hidden via SetHidden, for admin views.
+	IncludeHidden bool
+	// CaseSensitive makes the default searcher compare the query against
+	// tool names and namespaces without lowercasing. Description and tag
+	// text is indexed lowercased, so it is not matched in this mode. Custom
+	// searchers ignore this field.
+	CaseSensitive bool
+	// RequireOutputSchema omits tools that do not declare an OutputSchema,
+	// for planners that chain one tool's output into another's input.
+	RequireOutputSchema bool
+	// ExcludeNamespaces omits tools in any of the listed namespaces, for
+	// example a sandbox namespace that should not appear in discovery.
+	ExcludeNamespaces []string
+	// Categories, when non-empty, restricts results to tools in one of the
+	// listed categories (see SetCategory). Entries are compared after
+	// normalization, so "Dev Ops" matches "dev-ops".
+	Categories []string
+	// RegistrationOrder passes docs to the searcher in registration order
+	// (see SearchDoc.RegisteredSeq) instead of ID order, so empty-query
+	// results from the default searcher list tools as they were first
+	// registered. Ranked results still sort by score, then ID.
+	RegistrationOrder bool
+	// MaxPerBackendKind, when positive, caps how many results whose default
+	// backend (as chosen by the backend selector) is of each kind appear, so
+	// a large MCP catalog cannot bury a few local tools. Relevance order is
+	// kept within and across kinds. Searchers implementing PagedSearcher
+	// paginate on their own and are not capped.
+	MaxPerBackendKind int
+}
+
+// matches reports whether a doc passes the filter. filterDocs normalizes
+// Categories before calling it.
+func (f SearchFilter) matches(doc SearchDoc) bool {
+	if doc.Hidden && !f.IncludeHidden {
+		return false
+	}
+	if f.ExcludeDeprecated && doc.Summary.Deprecated {
+		return false
+	}
+	if f.RequireOutputSchema && !doc.HasOutputSchema {
+		return false
+	}
+	if slices.Contains(f.ExcludeNamespaces, doc.Summary.Namespace) {
+		return false
+	}
+	if len(f.Categories) > 0 && !slices.Contains(f.Categories, doc.Summary.Category) {
+		return false
+	}
+	return true
+}
+
+// filterDocs returns the docs that pass the filter, reordered by registration
+// when filter.RegistrationOrder is set. Callers pass their own snapshot, which
+// may be sorted in place.
+func filterDocs(docs []SearchDoc, filter SearchFilter) []SearchDoc {
+	docs = selectDocs(docs, filter)
+	if filter.RegistrationOrder {
+		sort.SliceStable(docs, func(i, j int) bool {
+			return docs[i].RegisteredSeq < docs[j].RegisteredSeq
+		})
+	}
+	return docs
+}
+
+// selectDocs returns the docs that pass the filter, preserving order.
+// The input slice is returned unchanged when every doc passes.
+func selectDocs(docs []SearchDoc, filter SearchFilter) []SearchDoc {
+	if len(filter.Categories) > 0 {
+		filter.Categories = toolmodel.NormalizeTags(filter.Categories)
+		if len(filter.Categories) == 0 {
+			return []SearchDoc{} // no listed category can match
+		}
+	}
+	for i, doc := range docs {
+		if filter.matches(doc) {
+			continue
+		}
+		out := make([]SearchDoc, i, len(docs)-1)
+		copy(out, docs[:i])
+		for _, rest := range docs[i+1:] {
+			if filter.matches(rest) {
+				out = append(out, rest)
+			}
+		}
+		return out
+	}
+	return docs
+}
+
+// SearchFiltered performs a search restricted to tools that pass filter.
+func (idx *InMemoryIndex) SearchFiltered(query string, limit int, filter SearchFilter) ([]Summary, error) {
+	limit = idx.clampLimit(limit)
+	docs, version := idx.snapshotSearchDocs()
+	docs = filterDocs(docs, filter)
+	if filter.MaxPerBackendKind <= 0 {
+		results, _, err := idx.runSearch(idx.searcherFor(filter), query, limit, docs, version)
+		return results, err
+	}
+
+	if limit <= 0 {
+		return []Summary{}, nil
+	}
+	// Capped kinds free up slots, so rank every match before capping.
+	results, _, err := idx.runSearch(idx.searcherFor(filter), query, len(docs), docs, version)
+	if err != nil {
+		return nil, err
+	}
+	results = idx.capPerBackendKind(results, filter.MaxPerBackendKind)
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// capPerBackendKind keeps at most perKind results per default backend kind,
+// preserving order.
+func (idx *InMemoryIndex) capPerBackendKind(results []Summary, perKind int) []Summary {
+	idx.mu.RLock()
+	kinds := make([]toolmodel.BackendKind, len(results))
+	for i, r := range results {
+		if record, ok := idx.tools[r.ID]; ok {
+			backend, _ := SelectBackend(idx.backendSelector, record.backends)
+			kinds[i] = backend.Kind
+		}
+	}
+	idx.mu.RUnlock()
+
+	counts := make(map[toolmodel.BackendKind]int)
+	out := make([]Summary, 0, len(results))
+	for i, r := range results {
+		if counts[kinds[i]] >= perKind {
+			continue
+		}
+		counts[kinds[i]]++
+		out = append(out, r)
+	}
+	return out
+}
+
+// SearchWithWarnings performs a search like Search and also returns non-fatal
+// warnings. A warning is reported when the configured searcher failed and
+// SearcherFallback produced the results, so callers can surface degraded
+// ranking instead of failing the request.
+func (idx *InMemoryIndex) SearchWithWarnings(query string, limit int) ([]Summary, []string, error) {
+	limit = idx.clampLimit(limit)
+	docs, version := idx.snapshotSearchDocs()
+	docs = filterDocs(docs, SearchFilter{})
+	results, primaryErr, err := idx.runSearch(idx.activeSearcher(), query, limit, docs, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	var warnings []string
+	if primaryErr != nil {
+		warnings = append(warnings, fmt.Sprintf("degraded: searcher failed (%v); results from fallback searcher", primaryErr))
+	}
+	return results, warnings, nil
+}
+
+// SearchIDs performs a search like Search but returns only the ranked tool
+// IDs, for callers that hydrate details selectively afterwards. The default
+// searcher ranks identically and skips building the summary slice.
+func (idx *InMemoryIndex) SearchIDs(query string, limit int) ([]string, error) {
+	limit = idx.clampLimit(limit)
+	docs, version := idx.snapshotSearchDocs()
+	docs = filterDocs(docs, SearchFilter{})
+	searcher := idx.activeSearcher()
+	if searcher == Searcher(idx.lexical) {
+		return idx.lexical.searchIDs(query, limit, docs), nil
+	}
+
+	results, _, err := idx.runSearch(searcher, query, limit, docs, version)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids, nil
+}
+
+// SearchGroup is one namespace's share of a grouped search.
+type SearchGroup struct {
+	Namespace string
+	Results   []Summary
+}
+
+// SearchGrouped runs a search and buckets the matches by namespace, keeping
+// relevance order within each group and capping each at limitPerGroup, which
+// is clamped like any search limit (see IndexOptions.MaxSearchLimit). Only the
+// top limitPerGroup results per namespace overall are ranked, so a group may
+// come up short when other namespaces dominate the ranking. Groups are
+// ordered by the rank of their best result.
+func (idx *InMemoryIndex) SearchGrouped(query string, limitPerGroup int) ([]SearchGroup, error) {
+	limitPerGroup = idx.clampLimit(limitPerGroup)
+	if limitPerGroup <= 0 {
+		return []SearchGroup{}, nil
+	}
+
+	docs, version := idx.snapshotSearchDocs()
+	docs = filterDocs(docs, SearchFilter{})
+	namespaces := make(map[string]struct{})
+	for _, doc := range docs {
+		namespaces[doc.Summary.Namespace] = struct{}{}
+	}
+	window := min(len(docs), limitPerGroup*len(namespaces))
+	results, _, err := idx.runSearch(idx.activeSearcher(), query, window, docs, version)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []SearchGroup{}
+	positions := make(map[string]int)
+	for _, r := range results {
+		i, ok := positions[r.Namespace]
+		if !ok {
+			i = len(groups)
+			positions[r.Namespace] = i
+			groups = append(groups, SearchGroup{Namespace: r.Namespace})
+		}
+		if len(groups[i].Results) < limitPerGroup {
+			groups[i].Results = append(groups[i].Results, r)
+		}
+	}
+	return groups, nil
+}
+
+// Overview returns search results and the visible namespaces taken from one
+// consistent snapshot, along with the index version they reflect. Unlike
+// separate Search and ListNamespaces calls, a mutation cannot land between
+// the two; compare versions across calls to detect change.
+func (idx *InMemoryIndex) Overview(query string, limit int) ([]Summary, []string, uint64, error) {
+	limit = idx.clampLimit(limit)
+	var namespaces []string
+	docs, version := idx.snapshotSearchDocsWith(func() {
+		namespaces = idx.visibleNamespacesLocked()
+	})
+	sort.Strings(namespaces)
+
+	docs = filterDocs(docs, SearchFilter{})
+	results, _, err := idx.runSearch(idx.activeSearcher(), query, limit, docs, version)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return results, namespaces, version, nil
+}
+
+// clampLimit applies IndexOptions.MaxSearchLimit to a requested limit.
+func (idx *InMemoryIndex) clampLimit(limit int) int {
+	if idx.maxSearchLimit > 0 && limit > idx.maxSearchLimit {
+		return idx.maxSearchLimit
+	}
+	return limit
+}
+
+// searcherFor returns the active searcher adjusted for the filter's
+// searcher-level options.
+func (idx *InMemoryIndex) searcherFor(filter SearchFilter) Searcher {
+	searcher := idx.activeSearcher()
+	if filter.CaseSensitive && searcher == Searcher(idx.lexical) {
+		exact := *idx.lexical
+		exact.caseSensitive = true
+		return &exact
+	}
+	return searcher
+}
+
+// runSearch executes searcher and, if it fails and a SearcherFallback is
+// configured, retries with the fallback. When the fallback produced the
+// results, primaryErr is the error the configured searcher returned.
+// VersionedSearchers receive the snapshot version alongside the docs.
+func (idx *InMemoryIndex) runSearch(searcher Searcher, query string, limit int, docs []SearchDoc, version uint64) (results []Summary, primaryErr error, err error) {
+	if vs, ok := searcher.(VersionedSearcher); ok {
+		results, err = vs.SearchVersioned(query, limit, docs, version)
+	} else {
+		results, err = searcher.Search(query, limit, docs)
+	}
+	if err == nil || idx.searcherFallback == nil {
+		return results, nil, err
+	}
+	if idx.onSearcherError != nil {
+		idx.onSearcherError(err)
+	}
+	results, fallbackErr := idx.searcherFallback.Search(query, limit, docs)
+	if fallbackErr != nil {
+		return nil, err, fmt.Errorf("searcher failed: %w; fallback failed: %v", err, fallbackErr)
+	}
+	return results, err, nil
+}
+
+// SearchPageFiltered performs a filtered search with cursor pagination.
+func (idx *InMemoryIndex) SearchPageFiltered(query string, limit int, cursor string, filter SearchFilter) ([]Summary, string, error) {
+	if limit <= 0 {
+		return nil, "", fmt.Errorf("limit must be positive")
+	}
+	limit = idx.clampLimit(limit)
+
+	docs, version := idx.snapshotSearchDocs()
+	docs = filterDocs(docs, filter)
+	searcher := idx.searcherFor(filter)
+
+	// Searchers that paginate natively own the cursor contract.
+	if ps, ok := searcher.(PagedSearcher); ok {
+		return ps.SearchPage(query, limit, cursor, docs)
+	}
+
+	if idx.requireDeterministicSearcher {
+		if ds, ok := searcher.(DeterministicSearcher); !ok || !ds.Deterministic() {
+			return nil, "", ErrNonDeterministicSearcher
+		}
+	}
+	results, _, err := idx.runSearch(searcher, query, len(docs), docs, version)
+	if err != nil {
+		return nil, "", err
+	}
+	if filter.MaxPerBackendKind > 0 {
+		results = idx.capPerBackendKind(results, filter.MaxPerBackendKind)
+	}
+
+	page, nextCursor, err := paginateResults(results, limit, cursor, version)
+	if err != nil {
+		return nil, "", err
+	}
+	return page, nextCursor, nil
+}
+
+// SearchDocsSnapshot returns a deep copy of the current search docs sorted by
+// ID.
+// It is intended for mirroring the index into an external search engine;
+// pair it with OnChange to know when to take a new snapshot.
+func (idx *InMemoryIndex) SearchDocsSnapshot() []SearchDoc {
+	docs, _ := idx.snapshotSearchDocs()
+	for i := range docs {
+		docs[i].Summary.Tags = slices.Clone(docs[i].Summary.Tags)
+		docs[i].Tokens = slices.Clone(docs[i].Tokens)
+		docs[i].Fields = docs[i].Fields.clone()
+	}
+	return docs
+}
+
+// SnapshotSummaries returns the summaries of all visible tools sorted by ID,
+// for shipping a browse-only catalog. It reads the cached per-tool summaries
+// under a read lock and never triggers a search doc rebuild.
+func (idx *InMemoryIndex) SnapshotSummaries() []Summary {
+	idx.mu.RLock()
+	summaries := make([]Summary, 0, len(idx.tools))
+	for _, record := range idx.tools {
+		if record.hidden {
+			continue
+		}
+		summary := record.summary
+		summary.Tags = slices.Clone(summary.Tags)
+		summaries = append(summaries, summary)
+	}
+	idx.mu.RUnlock()
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].ID < summaries[j].ID
+	})
+	return summaries
+}
+
+// ScoreComponent is one contribution to a result's relevance score.
+type ScoreComponent struct {
+	Reason string `json:"reason"`
+	Points int    `json:"points"`
+}
+
+// Explanation pairs a search result with its score and how it was computed.
+type Explanation struct {
+	Summary    Summary          `json:"summary"`
+	Score      int              `json:"score"`
+	Components []ScoreComponent `json:"components,omitempty"`
+}
+
+// Explainer is an optional interface for searchers that can explain ranking.
+//
+// Contract:
+// - Explain must rank identically to Search for the same inputs.
+// - Score and Components are searcher-defined and for diagnostics only.
+type Explainer interface {
+	Explain(query string, limit int, docs []SearchDoc) ([]Explanation, error)
+}
+
+// SearchExplain runs a search and returns each result with its relevance
+// breakdown. Like Search, it excludes hidden tools and honors
+// SearcherFallback. Searchers that do not implement Explainer yield their
+// results with a zero score and no components.
+func (idx *InMemoryIndex) SearchExplain(query string, limit int) ([]Explanation, error) {
+	limit = idx.clampLimit(limit)
+	docs, version := idx.snapshotSearchDocs()
+	docs = filterDocs(docs, SearchFilter{})
+	searcher := idx.activeSearcher()
+	if ex, ok := searcher.(Explainer); ok {
+		return ex.Explain(query, limit, docs)
+	}
+
+	results, _, err := idx.runSearch(searcher, query, limit, docs, version)
+	if err != nil {
+		return nil, err
+	}
+	explanations := make([]Explanation, len(results))
+	for i, r := range results {
+		explanations[i] = Explanation{Summary: r}
+	}
+	return explanations, nil
+}
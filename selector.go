@@ -0,0 +1,98 @@
+package toolindex
+
+import (
+	"maps"
+
+	"github.com/jonwraymond/toolmodel"
+)
+
+// SelectBackend applies selector to backends and reports whether it produced
+// a usable backend. It returns false for an empty backend list or when the
+// selector returns a zero-value backend.
+func SelectBackend(selector BackendSelector, backends []toolmodel.ToolBackend) (toolmodel.ToolBackend, bool) {
+	if len(backends) == 0 {
+		return toolmodel.ToolBackend{}, false
+	}
+	backend := selector(backends)
+	if backend.Kind == "" {
+		return toolmodel.ToolBackend{}, false
+	}
+	return backend, true
+}
+
+// PriorityBackendSelector returns a BackendSelector that prefers backend kinds
+// in the given order. Within a kind, the earliest registered backend wins.
+// If no backend matches any listed kind, the first backend is returned,
+// matching DefaultBackendSelector.
+//
+// For example, PriorityBackendSelector([]toolmodel.BackendKind{
+// toolmodel.BackendKindMCP, toolmodel.BackendKindLocal}) routes to MCP first.
+func PriorityBackendSelector(order []toolmodel.BackendKind) BackendSelector {
+	order = append([]toolmodel.BackendKind(nil), order...)
+	return func(backends []toolmodel.ToolBackend) toolmodel.ToolBackend {
+		if len(backends) == 0 {
+			return toolmodel.ToolBackend{}
+		}
+		for _, kind := range order {
+			for _, b := range backends {
+				if b.Kind == kind {
+					return b
+				}
+			}
+		}
+		return backends[0]
+	}
+}
+
+// WeightedBackendSelector returns a BackendSelector that picks the backend
+// whose kind has the highest weight; kinds missing from weights weigh zero.
+// Ties, including several backends of one kind, go to the backend with the
+// smallest identity key (the one UnregisterBackend matches on), so the
+// choice does not depend on registration order.
+//
+// For example, WeightedBackendSelector(map[toolmodel.BackendKind]int{
+// toolmodel.BackendKindProvider: 2, toolmodel.BackendKindMCP: 1}) prefers
+// provider backends and falls back to MCP.
+func WeightedBackendSelector(weights map[toolmodel.BackendKind]int) BackendSelector {
+	weights = maps.Clone(weights)
+	return func(backends []toolmodel.ToolBackend) toolmodel.ToolBackend {
+		if len(backends) == 0 {
+			return toolmodel.ToolBackend{}
+		}
+		best := backends[0]
+		bestKey := backendIdentity(best)
+		for _, b := range backends[1:] {
+			key := backendIdentity(b)
+			if w, bw := weights[b.Kind], weights[best.Kind]; w > bw || (w == bw && key < bestKey) {
+				best, bestKey = b, key
+			}
+		}
+		return best
+	}
+}
+
+// rankBackends orders backends by repeatedly applying selector to the
+// remaining candidates, so the first element is the selector's choice.
+// Backends the selector never picks keep their registration order.
+func rankBackends(selector BackendSelector, backends []toolmodel.ToolBackend) []toolmodel.ToolBackend {
+	remaining := make([]toolmodel.ToolBackend, len(backends))
+	copy(remaining, backends)
+	ranked := make([]toolmodel.ToolBackend, 0, len(backends))
+
+	for len(remaining) > 0 {
+		chosenKey := backendIdentity(selector(remaining))
+		pos := -1
+		for i, b := range remaining {
+			if backendIdentity(b) == chosenKey {
+				pos = i
+				break
+			}
+		}
+		if pos == -1 {
+			break
+		}
+		ranked = append(ranked, remaining[pos])
+		remaining = append(remaining[:pos], remaining[pos+1:]...)
+	}
+	return append(ranked, remaining...)
+}
@@ -0,0 +1,202 @@
+package toolindex
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jonwraymond/toolmodel"
+)
+
+func TestPriorityBackendSelector(t *testing.T) {
+	selector := PriorityBackendSelector([]toolmodel.BackendKind{
+		toolmodel.BackendKindMCP,
+		toolmodel.BackendKindLocal,
+	})
+
+	backends := []toolmodel.ToolBackend{
+		makeLocalBackend("handler"),
+		makeProviderBackend("p", "t"),
+		makeMCPBackend("server-a"),
+		makeMCPBackend("server-b"),
+	}
+	got := selector(backends)
+	if got.Kind != toolmodel.BackendKindMCP || got.MCP.ServerName != "server-a" {
+		t.Fatalf("expected first MCP backend, got %+v", got)
+	}
+
+	// Kinds missing from the order fall back to the first backend.
+	got = selector([]toolmodel.ToolBackend{makeProviderBackend("p1", "t"), makeProviderBackend("p2", "t")})
+	if got.Provider == nil || got.Provider.ProviderID != "p1" {
+		t.Fatalf("expected first backend fallback, got %+v", got)
+	}
+
+	if got := selector(nil); got.Kind != "" {
+		t.Fatalf("expected zero backend for empty input, got %+v", got)
+	}
+}
+
+func TestPriorityBackendSelector_WithIndex(t *testing.T) {
+	idx := NewInMemoryIndex(IndexOptions{
+		BackendSelector: PriorityBackendSelector([]toolmodel.BackendKind{toolmodel.BackendKindMCP}),
+	})
+	tool := makeTestTool("calc", "math", "Calculator", nil)
+	mustRegister(t, idx, tool, makeLocalBackend("calc"))
+	mustRegister(t, idx, tool, makeMCPBackend("math-server"))
+
+	_, backend, err := idx.GetTool("math:calc")
+	if err != nil {
+		t.Fatalf("GetTool failed: %v", err)
+	}
+	if backend.Kind != toolmodel.BackendKindMCP {
+		t.Fatalf("expected MCP backend, got %v", backend.Kind)
+	}
+}
+
+func TestWeightedBackendSelector(t *testing.T) {
+	selector := WeightedBackendSelector(map[toolmodel.BackendKind]int{
+		toolmodel.BackendKindProvider: 2,
+		toolmodel.BackendKindMCP:      1,
+	})
+
+	backends := []toolmodel.ToolBackend{
+		makeLocalBackend("handler"),
+		makeMCPBackend("server"),
+		makeProviderBackend("p2", "t"),
+		makeProviderBackend("p1", "t"),
+	}
+	got := selector(backends)
+	if got.Provider == nil || got.Provider.ProviderID != "p1" {
+		t.Fatalf("expected highest-weight backend with smallest identity, got %+v", got)
+	}
+	// Ties resolve the same way regardless of registration order.
+	reversed := []toolmodel.ToolBackend{backends[3], backends[2], backends[1], backends[0]}
+	if again := selector(reversed); backendIdentity(again) != backendIdentity(got) {
+		t.Fatalf("expected order-independent choice, got %+v", again)
+	}
+
+	if got := selector(backends[:2]); got.Kind != toolmodel.BackendKindMCP {
+		t.Fatalf("expected MCP fallback, got %+v", got)
+	}
+	// Unweighted kinds weigh zero and still tie-break deterministically.
+	if got := selector([]toolmodel.ToolBackend{makeLocalBackend("b"), makeLocalBackend("a")}); got.Local.Name != "a" {
+		t.Fatalf("expected smallest identity among unweighted backends, got %+v", got)
+	}
+	if got := selector(nil); got.Kind != "" {
+		t.Fatalf("expected zero backend for empty input, got %+v", got)
+	}
+
+	idx := NewInMemoryIndex(IndexOptions{BackendSelector: selector})
+	tool := makeTestTool("calc", "math", "Calculator", nil)
+	mustRegister(t, idx, tool, makeLocalBackend("calc"))
+	mustRegister(t, idx, tool, makeMCPBackend("math-server"))
+	if _, backend, _ := idx.GetTool("math:calc"); backend.Kind != toolmodel.BackendKindMCP {
+		t.Fatalf("expected MCP backend through IndexOptions, got %v", backend.Kind)
+	}
+}
+
+func TestGetBackendsRanked(t *testing.T) {
+	idx := NewInMemoryIndex()
+	tool := makeTestTool("calc", "math", "Calculator", nil)
+	mustRegister(t, idx, tool, makeMCPBackend("server"))
+	mustRegister(t, idx, tool, makeProviderBackend("p", "calc"))
+	mustRegister(t, idx, tool, makeLocalBackend("calc"))
+
+	ranked, err := idx.GetBackendsRanked("math:calc")
+	if err != nil {
+		t.Fatalf("GetBackendsRanked failed: %v", err)
+	}
+	want := []toolmodel.BackendKind{toolmodel.BackendKindLocal, toolmodel.BackendKindProvider, toolmodel.BackendKindMCP}
+	if len(ranked) != len(want) {
+		t.Fatalf("expected %d backends, got %d", len(want), len(ranked))
+	}
+	for i, kind := range want {
+		if ranked[i].Kind != kind {
+			t.Errorf("ranked[%d] = %v, want %v", i, ranked[i].Kind, kind)
+		}
+	}
+
+	if _, err := idx.GetBackendsRanked("math:missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestGetTool_EmptySelectionReturnsErrNoBackend(t *testing.T) {
+	idx := NewInMemoryIndex(IndexOptions{
+		BackendSelector: func([]toolmodel.ToolBackend) toolmodel.ToolBackend {
+			return toolmodel.ToolBackend{}
+		},
+	})
+	mustRegister(t, idx, makeTestTool("calc", "math", "Calculator", nil), makeLocalBackend("calc"))
+
+	_, backend, err := idx.GetTool("math:calc")
+	if !errors.Is(err, ErrNoBackend) {
+		t.Fatalf("expected ErrNoBackend, got %v", err)
+	}
+	if backend.Kind != "" {
+		t.Fatalf("expected zero backend, got %+v", backend)
+	}
+}
+
+func TestSelectBackend(t *testing.T) {
+	if _, ok := SelectBackend(DefaultBackendSelector, nil); ok {
+		t.Error("expected no selection for empty backends")
+	}
+	backend, ok := SelectBackend(DefaultBackendSelector, []toolmodel.ToolBackend{makeMCPBackend("s")})
+	if !ok || backend.Kind != toolmodel.BackendKindMCP {
+		t.Errorf("unexpected selection: %+v, %v", backend, ok)
+	}
+}
+
+func TestSetBackendSelector(t *testing.T) {
+	idx := NewInMemoryIndex()
+	tool := makeTestTool("calc", "math", "Calculator", nil)
+	mustRegister(t, idx, tool, makeLocalBackend("calc"))
+	mustRegister(t, idx, tool, makeMCPBackend("math-server"))
+
+	_, backend, _ := idx.GetTool("math:calc")
+	if backend.Kind != toolmodel.BackendKindLocal {
+		t.Fatalf("expected default local backend, got %v", backend.Kind)
+	}
+
+	idx.SetBackendSelector(PriorityBackendSelector([]toolmodel.BackendKind{toolmodel.BackendKindMCP}))
+	_, backend, _ = idx.GetTool("math:calc")
+	if backend.Kind != toolmodel.BackendKindMCP {
+		t.Fatalf("expected MCP backend after swap, got %v", backend.Kind)
+	}
+
+	idx.SetBackendSelector(nil)
+	_, backend, _ = idx.GetTool("math:calc")
+	if backend.Kind != toolmodel.BackendKindLocal {
+		t.Fatalf("expected default selector after reset, got %v", backend.Kind)
+	}
+}
+
+func TestGetToolWith(t *testing.T) {
+	idx := NewInMemoryIndex()
+	tool := makeTestTool("calc", "math", "Calculator", nil)
+	mustRegister(t, idx, tool, makeLocalBackend("calc"))
+	mustRegister(t, idx, tool, makeMCPBackend("math-server"))
+
+	mcpFirst := PriorityBackendSelector([]toolmodel.BackendKind{toolmodel.BackendKindMCP})
+	_, backend, err := idx.GetToolWith("math:calc", mcpFirst)
+	if err != nil {
+		t.Fatalf("GetToolWith failed: %v", err)
+	}
+	if backend.Kind != toolmodel.BackendKindMCP {
+		t.Fatalf("expected per-call MCP backend, got %v", backend.Kind)
+	}
+
+	// The index-wide selector is unchanged, and nil uses it.
+	for _, get := range []func() (toolmodel.Tool, toolmodel.ToolBackend, error){
+		func() (toolmodel.Tool, toolmodel.ToolBackend, error) { return idx.GetTool("math:calc") },
+		func() (toolmodel.Tool, toolmodel.ToolBackend, error) { return idx.GetToolWith("math:calc", nil) },
+	} {
+		if _, backend, _ := get(); backend.Kind != toolmodel.BackendKindLocal {
+			t.Fatalf("expected default local backend, got %v", backend.Kind)
+		}
+	}
+
+	if _, _, err := idx.GetToolWith("math:missing", mcpFirst); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
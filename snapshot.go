@@ -0,0 +1,203 @@
+package toolindex
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+
+	"github.com/jonwraymond/toolmodel"
+)
+
+// snapshotFormatVersion is the version of the JSON snapshot format:
+//
+//	{"version": 1, "tools": [{"tool": {...}, "backends": [{...}]}]}
+//
+// Each entry holds a toolmodel.Tool and all of its backends, plus any curation
+// state (deprecation, hidden, popularity, category); curation fields are
+// omitted when unset, so snapshots without them remain valid.
+const snapshotFormatVersion = 1
+
+// snapshotEntry is one tool, its backends, and its curation state in a
+// snapshot.
+type snapshotEntry struct {
+	Tool        toolmodel.Tool          `json:"tool"`
+	Backends    []toolmodel.ToolBackend `json:"backends"`
+	Deprecation *snapshotDeprecation    `json:"deprecation,omitempty"`
+	Hidden      bool                    `json:"hidden,omitempty"`
+	Popularity  float64                 `json:"popularity,omitempty"`
+	Category    string                  `json:"category,omitempty"`
+}
+
+// snapshotDeprecation is the deprecation state set by Deprecate.
+type snapshotDeprecation struct {
+	ReplacedBy string `json:"replacedBy,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// WriteSnapshot streams a snapshot of every tool, its backends, and its
+// curation state to w, in tool ID order. Entries are captured under a
+// single read lock so the output is consistent, then encoded one at a
+// time without holding the lock or materializing the whole document. The
+// output is readable by LoadSnapshotStream.
+func (idx *InMemoryIndex) WriteSnapshot(w io.Writer) error {
+	idx.mu.RLock()
+	ids := make([]string, 0, len(idx.tools))
+	for id := range idx.tools {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	entries := make([]snapshotEntry, len(ids))
+	for i, id := range ids {
+		record := idx.tools[id]
+		entries[i] = snapshotEntry{
+			Tool:       record.tool,
+			Backends:   slices.Clone(record.backends),
+			Hidden:     record.hidden,
+			Popularity: record.popularity,
+			Category:   record.category,
+		}
+		if record.deprecation != nil {
+			entries[i].Deprecation = &snapshotDeprecation{
+				ReplacedBy: record.deprecation.replacedBy,
+				Reason:     record.deprecation.reason,
+			}
+		}
+	}
+	idx.mu.RUnlock()
+
+	if _, err := fmt.Fprintf(w, `{"version":%d,"tools":[`, snapshotFormatVersion); err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	for i := range entries {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(entries[i]); err != nil {
+			return fmt.Errorf("snapshot: %s: %w", ids[i], err)
+		}
+	}
+	_, err := io.WriteString(w, "]}\n")
+	return err
+}
+
+// LoadSnapshotStream reads a snapshot from r and registers its tools one entry
+// at a time, so memory stays bounded by the largest single entry rather than
+// the whole snapshot. Entries are validated as they are registered; on the
+// first failure LoadSnapshotStream stops and reports the entry index and byte
+// offset. Tools registered before the failure remain registered. Curation
+// state is restored with each entry; a deprecation's replacement need not
+// have been loaded yet.
+func (idx *InMemoryIndex) LoadSnapshotStream(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		keyToken, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("snapshot: %w", err)
+		}
+		key, _ := keyToken.(string)
+
+		switch key {
+		case "version":
+			var version int
+			if err := dec.Decode(&version); err != nil {
+				return fmt.Errorf("snapshot: version: %w", err)
+			}
+			if version != snapshotFormatVersion {
+				return fmt.Errorf("snapshot: unsupported version %d", version)
+			}
+		case "tools":
+			if err := idx.loadSnapshotTools(dec); err != nil {
+				return err
+			}
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return fmt.Errorf("snapshot: %s: %w", key, err)
+			}
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+// loadSnapshotTools decodes and registers the entries of the "tools" array.
+func (idx *InMemoryIndex) loadSnapshotTools(dec *json.Decoder) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	for i := 0; dec.More(); i++ {
+		offset := dec.InputOffset()
+		var entry snapshotEntry
+		if err := dec.Decode(&entry); err != nil {
+			return fmt.Errorf("snapshot: tools[%d] at offset %d: %w", i, offset, err)
+		}
+		if len(entry.Backends) == 0 {
+			return fmt.Errorf("%w: snapshot: tools[%d] at offset %d has no backends", ErrInvalidBackend, i, offset)
+		}
+		if err := idx.loadSnapshotEntry(entry); err != nil {
+			return fmt.Errorf("snapshot: tools[%d] at offset %d: %w", i, offset, err)
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+// loadSnapshotEntry registers one entry's backends and restores its curation
+// state.
+func (idx *InMemoryIndex) loadSnapshotEntry(entry snapshotEntry) error {
+	opts := registerOptions{hidden: entry.Hidden, category: entry.Category}
+	for _, backend := range entry.Backends {
+		if err := idx.registerTool(entry.Tool, backend, opts); err != nil {
+			return err
+		}
+	}
+	toolID := formatToolID(entry.Tool.Namespace, entry.Tool.Name)
+	if entry.Popularity != 0 {
+		if err := idx.SetPopularity(toolID, entry.Popularity); err != nil {
+			return err
+		}
+	}
+	if entry.Deprecation != nil {
+		idx.restoreDeprecation(toolID, entry.Deprecation.ReplacedBy, entry.Deprecation.Reason)
+	}
+	return nil
+}
+
+// restoreDeprecation marks a just-loaded tool deprecated without requiring
+// the replacement to be registered, since it may appear later in a snapshot.
+func (idx *InMemoryIndex) restoreDeprecation(toolID, replacementID, reason string) {
+	idx.mu.Lock()
+	record, exists := idx.tools[toolID]
+	if !exists {
+		idx.mu.Unlock()
+		return
+	}
+	record.deprecation = &deprecation{replacedBy: replacementID, reason: reason}
+	idx.refreshRecordDerived(record)
+	idx.rehashLocked(record)
+	idx.updateSearchDocsLocked(toolID)
+	version := idx.indexVersion
+	listeners := idx.snapshotListenersLocked()
+	idx.mu.Unlock()
+
+	notifyListeners(listeners, ChangeEvent{Type: ChangeUpdated, ToolID: toolID, Version: version})
+}
+
+// expectDelim reads the next token and checks that it is the given delimiter.
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+	if got, ok := tok.(json.Delim); !ok || got != want {
+		return fmt.Errorf("snapshot: expected %q at offset %d, got %v", want, dec.InputOffset(), tok)
+	}
+	return nil
+}
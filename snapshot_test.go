@@ -0,0 +1,163 @@
+package toolindex
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testSnapshot = `{
+  "version": 1,
+  "tools": [
+    {
+      "tool": {"name": "add", "namespace": "math", "description": "Add numbers", "inputSchema": {"type": "object"}, "tags": ["Arithmetic"]},
+      "backends": [{"kind": "local", "local": {"name": "add"}}, {"kind": "mcp", "mcp": {"serverName": "math"}}]
+    },
+    {
+      "tool": {"name": "fetch", "namespace": "web", "description": "Fetch a page", "inputSchema": {"type": "object"}},
+      "backends": [{"kind": "mcp", "mcp": {"serverName": "web"}}]
+    }
+  ]
+}`
+
+func TestLoadSnapshotStream(t *testing.T) {
+	idx := NewInMemoryIndex()
+	if err := idx.LoadSnapshotStream(strings.NewReader(testSnapshot)); err != nil {
+		t.Fatalf("LoadSnapshotStream failed: %v", err)
+	}
+
+	backends, err := idx.GetAllBackends("math:add")
+	if err != nil {
+		t.Fatalf("GetAllBackends failed: %v", err)
+	}
+	if len(backends) != 2 {
+		t.Fatalf("expected 2 backends, got %d", len(backends))
+	}
+	results, _ := idx.Search("arithmetic", 10)
+	if len(results) != 1 || results[0].ID != "math:add" {
+		t.Fatalf("expected normalized tag search to work, got %+v", results)
+	}
+	if _, _, err := idx.GetTool("web:fetch"); err != nil {
+		t.Fatalf("GetTool failed: %v", err)
+	}
+}
+
+func TestLoadSnapshotStream_ReportsOffendingEntry(t *testing.T) {
+	snapshot := `{"version": 1, "tools": [
+      {"tool": {"name": "ok", "inputSchema": {}}, "backends": [{"kind": "local", "local": {"name": "ok"}}]},
+      {"tool": {"name": "", "inputSchema": {}}, "backends": [{"kind": "local", "local": {"name": "bad"}}]}
+    ]}`
+
+	idx := NewInMemoryIndex()
+	err := idx.LoadSnapshotStream(strings.NewReader(snapshot))
+	if !errors.Is(err, ErrInvalidTool) {
+		t.Fatalf("expected ErrInvalidTool, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "tools[1]") {
+		t.Fatalf("expected error to name the entry, got %v", err)
+	}
+	if _, _, err := idx.GetTool("ok"); err != nil {
+		t.Fatalf("entries before the failure should be registered: %v", err)
+	}
+}
+
+func TestLoadSnapshotStream_RejectsUnknownVersion(t *testing.T) {
+	idx := NewInMemoryIndex()
+	if err := idx.LoadSnapshotStream(strings.NewReader(`{"version": 2, "tools": []}`)); err == nil {
+		t.Fatal("expected error for unsupported version")
+	}
+}
+
+func TestWriteSnapshot_RoundTrip(t *testing.T) {
+	src := NewInMemoryIndex()
+	if err := src.LoadSnapshotStream(strings.NewReader(testSnapshot)); err != nil {
+		t.Fatalf("LoadSnapshotStream failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := src.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("WriteSnapshot failed: %v", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		t.Fatalf("snapshot is not valid JSON: %s", buf.String())
+	}
+
+	dst := NewInMemoryIndex()
+	if err := dst.LoadSnapshotStream(&buf); err != nil {
+		t.Fatalf("reloading snapshot failed: %v", err)
+	}
+	for _, id := range []string{"math:add", "web:fetch"} {
+		want, _ := src.GetAllBackends(id)
+		got, err := dst.GetAllBackends(id)
+		if err != nil {
+			t.Fatalf("GetAllBackends(%s) failed: %v", id, err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("%s: expected %d backends, got %d", id, len(want), len(got))
+		}
+	}
+}
+
+func TestWriteSnapshot_RoundTripsCuration(t *testing.T) {
+	src := NewInMemoryIndex()
+	if err := src.LoadSnapshotStream(strings.NewReader(testSnapshot)); err != nil {
+		t.Fatalf("LoadSnapshotStream failed: %v", err)
+	}
+	// math:add sorts first, so its replacement is loaded after it.
+	if err := src.Deprecate("math:add", "web:fetch", "moved"); err != nil {
+		t.Fatalf("Deprecate failed: %v", err)
+	}
+	if err := src.SetHidden("web:fetch", true); err != nil {
+		t.Fatalf("SetHidden failed: %v", err)
+	}
+	if err := src.SetPopularity("math:add", 4.5); err != nil {
+		t.Fatalf("SetPopularity failed: %v", err)
+	}
+	if err := src.SetCategory("math:add", "Numbers"); err != nil {
+		t.Fatalf("SetCategory failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := src.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("WriteSnapshot failed: %v", err)
+	}
+	dst := NewInMemoryIndex()
+	if err := dst.LoadSnapshotStream(&buf); err != nil {
+		t.Fatalf("reloading snapshot failed: %v", err)
+	}
+
+	summary, err := dst.GetSummary("math:add")
+	if err != nil {
+		t.Fatalf("GetSummary failed: %v", err)
+	}
+	if !summary.Deprecated || summary.ReplacedBy != "web:fetch" || summary.DeprecationReason != "moved" {
+		t.Fatalf("expected deprecation to round-trip, got %+v", summary)
+	}
+	if summary.Category != "numbers" {
+		t.Fatalf("expected category to round-trip, got %q", summary.Category)
+	}
+	if results, _ := dst.Search("fetch", 10); len(results) != 0 {
+		t.Fatalf("expected hidden tool to stay hidden, got %+v", results)
+	}
+	dst.mu.RLock()
+	popularity := dst.tools["math:add"].popularity
+	dst.mu.RUnlock()
+	if popularity != 4.5 {
+		t.Fatalf("expected popularity to round-trip, got %v", popularity)
+	}
+	if src.Fingerprint() != dst.Fingerprint() {
+		t.Fatal("expected restored index to match the source fingerprint")
+	}
+}
+
+func TestWriteSnapshot_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewInMemoryIndex().WriteSnapshot(&buf); err != nil {
+		t.Fatalf("WriteSnapshot failed: %v", err)
+	}
+	if got := strings.TrimSpace(buf.String()); got != `{"version":1,"tools":[]}` {
+		t.Fatalf("unexpected empty snapshot: %s", got)
+	}
+}
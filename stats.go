@@ -0,0 +1,47 @@
+package toolindex
+
+import "time"
+
+// IndexStats is a point-in-time view of index size and cache behavior.
+type IndexStats struct {
+	Tools      int
+	Namespaces int
+	// Version is the current index version (incremented on every mutation).
+	Version uint64
+	// SearchDocBuilds counts full rebuilds of the search doc cache.
+	SearchDocBuilds int
+	// SearchDocPatches counts single-tool mutations applied to a current
+	// search doc cache in place, without a full rebuild.
+	SearchDocPatches int
+	// LastRebuildDuration is how long the most recent search doc rebuild took.
+	LastRebuildDuration time.Duration
+	// LastRebuildDocCount is the number of docs the most recent rebuild produced.
+	LastRebuildDocCount int
+	// SearchDocCacheHits counts searches served from the cached doc snapshot.
+	SearchDocCacheHits uint64
+	// SearchDocCacheMisses counts searches that found the cache stale and took
+	// the rebuild path. A high ratio of misses to hits indicates thrashing.
+	SearchDocCacheMisses uint64
+	// SearchDocRebuildRetries counts search doc rebuilds, built outside the
+	// write lock, that were discarded because the index changed mid-build.
+	SearchDocRebuildRetries uint64
+}
+
+// Stats returns a snapshot of index statistics.
+func (idx *InMemoryIndex) Stats() IndexStats {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return IndexStats{
+		Tools:                   len(idx.tools),
+		Namespaces:              len(idx.namespaces),
+		Version:                 idx.indexVersion,
+		SearchDocBuilds:         idx.searchDocsBuilds,
+		SearchDocPatches:        idx.searchDocsPatches,
+		LastRebuildDuration:     idx.lastRebuildDuration,
+		LastRebuildDocCount:     idx.lastRebuildDocCount,
+		SearchDocCacheHits:      idx.snapshotHits.Load(),
+		SearchDocCacheMisses:    idx.snapshotMisses.Load(),
+		SearchDocRebuildRetries: idx.rebuildRetries.Load(),
+	}
+}
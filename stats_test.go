@@ -0,0 +1,53 @@
+package toolindex
+
+import "testing"
+
+func TestStats_RecordsLastRebuild(t *testing.T) {
+	idx := NewInMemoryIndex()
+
+	stats := idx.Stats()
+	if stats.SearchDocBuilds != 0 || stats.LastRebuildDocCount != 0 {
+		t.Fatalf("unexpected initial stats: %+v", stats)
+	}
+
+	mustRegister(t, idx, makeTestTool("a", "ns1", "A", nil), makeLocalBackend("a"))
+	mustRegister(t, idx, makeTestTool("b", "ns2", "B", nil), makeLocalBackend("b"))
+	if _, err := idx.Search("", 10); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	stats = idx.Stats()
+	if stats.Tools != 2 || stats.Namespaces != 2 {
+		t.Errorf("expected 2 tools in 2 namespaces, got %+v", stats)
+	}
+	if stats.SearchDocBuilds != 1 {
+		t.Errorf("expected 1 build, got %d", stats.SearchDocBuilds)
+	}
+	if stats.LastRebuildDocCount != 2 {
+		t.Errorf("expected last rebuild doc count 2, got %d", stats.LastRebuildDocCount)
+	}
+	if stats.LastRebuildDuration < 0 {
+		t.Errorf("expected non-negative duration, got %v", stats.LastRebuildDuration)
+	}
+}
+
+func TestStats_SearchDocCacheCounters(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("a", "ns", "A", nil), makeLocalBackend("a"))
+
+	_, _ = idx.Search("a", 10) // miss: cache is dirty
+	_, _ = idx.Search("a", 10) // hit
+	_, _ = idx.Search("b", 10) // hit
+	// Single-tool mutations patch the cache in place; bulk ones mark it stale.
+	mustRegister(t, idx, makeTestTool("b", "ns", "B", nil), makeLocalBackend("b"))
+	_, _ = idx.Search("b", 10) // hit after patch
+	if _, err := idx.TagNamespace("ns", "bulk"); err != nil {
+		t.Fatalf("TagNamespace failed: %v", err)
+	}
+	_, _ = idx.Search("b", 10) // miss after bulk mutation
+
+	stats := idx.Stats()
+	if stats.SearchDocCacheHits != 3 || stats.SearchDocCacheMisses != 2 {
+		t.Fatalf("expected 3 hits and 2 misses, got %d hits, %d misses", stats.SearchDocCacheHits, stats.SearchDocCacheMisses)
+	}
+}
@@ -0,0 +1,188 @@
+package toolindex
+
+import (
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/jonwraymond/toolmodel"
+)
+
+// SyncResult reports how ReplaceAll changed the index. ID lists are sorted.
+type SyncResult struct {
+	Added     []string
+	Updated   []string
+	Removed   []string
+	Unchanged int
+}
+
+// desiredTool is the target state of one tool in a ReplaceAll batch.
+type desiredTool struct {
+	tool           toolmodel.Tool
+	backends       []toolmodel.ToolBackend
+	backendKeys    map[string]int
+	normalizedTags []string
+	droppedTags    []string // removed by MaxTagsPerTool
+	hidden         bool
+	category       string
+}
+
+// ReplaceAll converges the index to exactly the tools in regs: tools not yet
+// registered are added, registered tools whose definition, tags, or backend
+// set differ are updated, and tools absent from regs are removed. Unchanged
+// tools are left alone and produce no events. Entries sharing a tool ID merge
+// their backends as in RegisterTools, and the batch is validated whole before
+// anything changes. The deltas are applied under a single lock, so readers
+// never observe a partially reconciled catalog. Like UpsertTool, updates
+// replace the backend set without the MCP-field consistency check, and
+// index-side curation (deprecation, popularity) is kept. An empty regs
+// removes every tool.
+func (idx *InMemoryIndex) ReplaceAll(regs []ToolRegistration) (SyncResult, error) {
+	if err := checkBatchConflicts(regs); err != nil {
+		return SyncResult{}, err
+	}
+	desired := make(map[string]*desiredTool, len(regs))
+	for _, reg := range regs {
+		if err := validateIndexTool(reg.Tool); err != nil {
+			return SyncResult{}, err
+		}
+		toolID := formatToolID(reg.Tool.Namespace, reg.Tool.Name)
+		if err := idx.validateToolBackend(toolID, reg.Backend); err != nil {
+			return SyncResult{}, err
+		}
+		normalizedTags, droppedTags, err := idx.ingestTags(toolID, reg.Tool.Tags, reg.TagsNormalized)
+		if err != nil {
+			return SyncResult{}, err
+		}
+		category, err := normalizeCategory(toolID, reg.Category)
+		if err != nil {
+			return SyncResult{}, err
+		}
+
+		d, ok := desired[toolID]
+		if !ok {
+			d = &desiredTool{backendKeys: make(map[string]int)}
+			desired[toolID] = d
+		}
+		// Later entries for the same tool win, as in RegisterTools.
+		d.tool = reg.Tool
+		d.normalizedTags = normalizedTags
+		d.droppedTags = droppedTags
+		d.hidden = d.hidden || reg.Hidden
+		if category != "" {
+			d.category = category
+		}
+		key := backendIdentity(reg.Backend)
+		if i, ok := d.backendKeys[key]; ok {
+			d.backends[i] = reg.Backend
+			continue
+		}
+		d.backendKeys[key] = len(d.backends)
+		d.backends = append(d.backends, reg.Backend)
+	}
+
+	ids := make([]string, 0, len(desired))
+	for id := range desired {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var result SyncResult
+	idx.mu.Lock()
+	now := idx.now()
+	var touched []*toolRecord
+	for _, id := range ids {
+		d := desired[id]
+		record, exists := idx.tools[id]
+		if exists && !d.differsFrom(record) {
+			record.lastSeen = now
+			result.Unchanged++
+			continue
+		}
+		if !exists {
+			record = &toolRecord{seq: idx.nextSeqLocked()}
+			idx.tools[id] = record
+			idx.addNamespaceLocked(d.tool.Namespace)
+			delete(idx.aliases, id)
+			result.Added = append(result.Added, id)
+		} else {
+			result.Updated = append(result.Updated, id)
+		}
+		record.tool = d.tool
+		record.backends = d.backends
+		record.backendKeys = d.backendKeys
+		record.normalizedTags = d.normalizedTags
+		if d.category != "" {
+			record.category = d.category
+		}
+		if d.hidden {
+			idx.setHiddenLocked(record, true)
+		}
+		idx.refreshRecordDerived(record)
+		idx.rehashLocked(record)
+		record.lastSeen = now
+		record.updatedAt = now
+		touched = append(touched, record)
+	}
+	for id, record := range idx.tools {
+		if _, ok := desired[id]; !ok {
+			idx.removeRecordLocked(id, record)
+			result.Removed = append(result.Removed, id)
+		}
+	}
+	sort.Strings(result.Removed)
+
+	if len(touched) == 0 && len(result.Removed) == 0 {
+		idx.mu.Unlock()
+		idx.reportDesiredTruncations(ids, desired)
+		return result, nil
+	}
+	idx.markSearchDocsDirtyLocked()
+	version := idx.indexVersion
+	for _, record := range touched {
+		record.backendsVersion = version
+	}
+	listeners := idx.snapshotListenersLocked()
+	idx.mu.Unlock()
+
+	idx.reportDesiredTruncations(ids, desired)
+	for _, id := range result.Added {
+		notifyListeners(listeners, ChangeEvent{Type: ChangeRegistered, ToolID: id, Version: version})
+	}
+	for _, id := range result.Updated {
+		notifyListeners(listeners, ChangeEvent{Type: ChangeUpdated, ToolID: id, Version: version})
+	}
+	for _, id := range result.Removed {
+		notifyListeners(listeners, ChangeEvent{Type: ChangeToolRemoved, ToolID: id, Version: version})
+	}
+	return result, nil
+}
+
+// reportDesiredTruncations reports tags MaxTagsPerTool dropped from the
+// reconciled tools, in ID order.
+func (idx *InMemoryIndex) reportDesiredTruncations(ids []string, desired map[string]*desiredTool) {
+	for _, id := range ids {
+		idx.reportTruncatedTags(id, desired[id].droppedTags)
+	}
+}
+
+// differsFrom reports whether applying d would change record. Backend order
+// is ignored; each identity must map to identical backend details.
+func (d *desiredTool) differsFrom(record *toolRecord) bool {
+	if !reflect.DeepEqual(record.tool, d.tool) || !slices.Equal(record.normalizedTags, d.normalizedTags) {
+		return true
+	}
+	if (d.hidden && !record.hidden) || (d.category != "" && d.category != record.category) {
+		return true
+	}
+	if len(record.backends) != len(d.backends) {
+		return true
+	}
+	for key, i := range d.backendKeys {
+		j, ok := record.backendKeys[key]
+		if !ok || !reflect.DeepEqual(record.backends[j], d.backends[i]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package toolindex
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jonwraymond/toolmodel"
+)
+
+func TestReplaceAll_AppliesMinimalDiff(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("keep", "ns", "Keep", nil), makeMCPBackend("s"))
+	mustRegister(t, idx, makeTestTool("change", "ns", "Old", nil), makeMCPBackend("s"))
+	mustRegister(t, idx, makeTestTool("gone", "ns", "Gone", nil), makeMCPBackend("s"))
+	if err := idx.SetPopularity("ns:change", 3); err != nil {
+		t.Fatalf("SetPopularity failed: %v", err)
+	}
+
+	var events []ChangeEvent
+	idx.OnChange(func(e ChangeEvent) { events = append(events, e) })
+
+	regs := []ToolRegistration{
+		{Tool: makeTestTool("keep", "ns", "Keep", nil), Backend: makeMCPBackend("s")},
+		{Tool: makeTestTool("change", "ns", "New", nil), Backend: makeMCPBackend("s")},
+		{Tool: makeTestTool("new", "other", "New tool", nil), Backend: makeMCPBackend("s")},
+		{Tool: makeTestTool("new", "other", "New tool", nil), Backend: makeLocalBackend("n")},
+	}
+	result, err := idx.ReplaceAll(regs)
+	if err != nil {
+		t.Fatalf("ReplaceAll failed: %v", err)
+	}
+	want := SyncResult{
+		Added:     []string{"other:new"},
+		Updated:   []string{"ns:change"},
+		Removed:   []string{"ns:gone"},
+		Unchanged: 1,
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Fatalf("ReplaceAll = %+v, want %+v", result, want)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %+v", events)
+	}
+	for _, e := range events {
+		if e.Version != events[0].Version {
+			t.Errorf("expected all events at one version, got %+v", events)
+		}
+	}
+
+	if _, _, err := idx.GetTool("ns:gone"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected removed tool to be gone, got %v", err)
+	}
+	if backends, _ := idx.GetAllBackends("other:new"); len(backends) != 2 {
+		t.Errorf("expected merged backends for other:new, got %+v", backends)
+	}
+	tool, _, _ := idx.GetTool("ns:change")
+	if tool.Description != "New" {
+		t.Errorf("expected updated description, got %q", tool.Description)
+	}
+	results, _ := idx.Search("change", 10)
+	if len(results) != 1 || results[0].ShortDescription != "New" {
+		t.Errorf("expected search to reflect the update, got %+v", results)
+	}
+	if docs := idx.SearchDocsSnapshot(); docs[0].Popularity != 3 {
+		t.Errorf("expected popularity to survive the update, got %+v", docs[0])
+	}
+
+	// Reapplying the same set is a no-op.
+	events = nil
+	version := idx.Stats().Version
+	result, err = idx.ReplaceAll(regs)
+	if err != nil {
+		t.Fatalf("ReplaceAll failed: %v", err)
+	}
+	if result.Unchanged != 3 || len(result.Added)+len(result.Updated)+len(result.Removed) != 0 {
+		t.Fatalf("expected no changes, got %+v", result)
+	}
+	if len(events) != 0 || idx.Stats().Version != version {
+		t.Fatalf("expected no events or version bump, got %+v", events)
+	}
+}
+
+func TestReplaceAll_ValidatesBeforeMutating(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("a", "ns", "A", nil), makeMCPBackend("s"))
+
+	_, err := idx.ReplaceAll([]ToolRegistration{
+		{Tool: makeTestTool("b", "ns", "B", nil), Backend: makeMCPBackend("s")},
+		{Tool: makeTestTool("c", "ns", "C", nil), Backend: toolmodel.ToolBackend{Kind: toolmodel.BackendKindMCP}},
+	})
+	if !errors.Is(err, ErrInvalidBackend) {
+		t.Fatalf("expected ErrInvalidBackend, got %v", err)
+	}
+	if ids := idx.ListToolIDs(); !reflect.DeepEqual(ids, []string{"ns:a"}) {
+		t.Fatalf("expected index unchanged after a rejected batch, got %v", ids)
+	}
+
+	result, err := idx.ReplaceAll(nil)
+	if err != nil {
+		t.Fatalf("ReplaceAll(nil) failed: %v", err)
+	}
+	if !reflect.DeepEqual(result.Removed, []string{"ns:a"}) || len(idx.ListToolIDs()) != 0 {
+		t.Fatalf("expected empty set to remove every tool, got %+v", result)
+	}
+}
@@ -0,0 +1,101 @@
+package toolindex
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/jonwraymond/toolmodel"
+)
+
+// UpstreamLoader fetches a tool and backend from an upstream registry by ID.
+type UpstreamLoader func(id string) (toolmodel.Tool, toolmodel.ToolBackend, error)
+
+// loadGroup collapses concurrent upstream loads for the same ID into one call
+// whose result is shared by every waiter (a minimal singleflight).
+type loadGroup struct {
+	mu    sync.Mutex
+	calls map[string]*loadCall
+}
+
+type loadCall struct {
+	wg      sync.WaitGroup
+	tool    toolmodel.Tool
+	backend toolmodel.ToolBackend
+	err     error
+}
+
+// do runs fn once per key among concurrent callers.
+func (g *loadGroup) do(key string, fn func() (toolmodel.Tool, toolmodel.ToolBackend, error)) (toolmodel.Tool, toolmodel.ToolBackend, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*loadCall)
+	}
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		call.wg.Wait()
+		return call.tool, call.backend, call.err
+	}
+	call := &loadCall{}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	g.run(key, call, fn)
+	return call.tool, call.backend, call.err
+}
+
+// run executes fn for call and releases its waiters. If fn panics, waiters
+// receive an error instead of blocking forever, and the panic is re-raised in
+// the calling goroutine.
+func (g *loadGroup) run(key string, call *loadCall, fn func() (toolmodel.Tool, toolmodel.ToolBackend, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.err = fmt.Errorf("upstream load %s panicked: %v", key, r)
+			g.finish(key, call)
+			panic(r)
+		}
+	}()
+	call.tool, call.backend, call.err = fn()
+	g.finish(key, call)
+}
+
+// finish wakes call's waiters and forgets key so later misses load again.
+func (g *loadGroup) finish(key string, call *loadCall) {
+	call.wg.Done()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+}
+
+// loadFromUpstream fetches id from the upstream loader, registers the result,
+// and returns the tool with its default backend. Concurrent misses for the
+// same ID share a single upstream call.
+func (idx *InMemoryIndex) loadFromUpstream(id string) (toolmodel.Tool, toolmodel.ToolBackend, error) {
+	return idx.upstreamLoads.do(id, func() (toolmodel.Tool, toolmodel.ToolBackend, error) {
+		// Another flight may have registered the tool while we waited.
+		if tool, backend, err := idx.getToolLocal(id); !errors.Is(err, ErrNotFound) {
+			return tool, backend, err
+		}
+		return idx.loadAndRegister(id)
+	})
+}
+
+// loadAndRegister performs one upstream load and registers the result.
+func (idx *InMemoryIndex) loadAndRegister(id string) (toolmodel.Tool, toolmodel.ToolBackend, error) {
+	tool, backend, err := idx.upstreamLoader(id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return toolmodel.Tool{}, toolmodel.ToolBackend{}, err
+		}
+		return toolmodel.Tool{}, toolmodel.ToolBackend{}, fmt.Errorf("upstream load %s: %w", id, err)
+	}
+	if loadedID := formatToolID(tool.Namespace, tool.Name); loadedID != id {
+		return toolmodel.Tool{}, toolmodel.ToolBackend{}, fmt.Errorf("%w: upstream returned %q for %q", ErrInvalidTool, loadedID, id)
+	}
+	if err := idx.RegisterTool(tool, backend); err != nil {
+		return toolmodel.Tool{}, toolmodel.ToolBackend{}, err
+	}
+	return idx.getToolLocal(id)
+}
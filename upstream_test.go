@@ -0,0 +1,176 @@
+package toolindex
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jonwraymond/toolmodel"
+)
+
+func TestUpstreamLoader_ReadThroughOnMiss(t *testing.T) {
+	calls := 0
+	loader := func(id string) (toolmodel.Tool, toolmodel.ToolBackend, error) {
+		calls++
+		if id != "remote:calc" {
+			return toolmodel.Tool{}, toolmodel.ToolBackend{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		return makeTestTool("calc", "remote", "Remote calculator", nil), makeMCPBackend("upstream"), nil
+	}
+	idx := NewInMemoryIndex(IndexOptions{UpstreamLoader: loader})
+
+	tool, backend, err := idx.GetTool("remote:calc")
+	if err != nil {
+		t.Fatalf("GetTool failed: %v", err)
+	}
+	if tool.Name != "calc" || backend.MCP == nil || backend.MCP.ServerName != "upstream" {
+		t.Fatalf("unexpected result: %+v %+v", tool, backend)
+	}
+
+	// Second lookup is served from the local cache.
+	if _, _, err := idx.GetTool("remote:calc"); err != nil {
+		t.Fatalf("cached GetTool failed: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 upstream call, got %d", calls)
+	}
+
+	if _, _, err := idx.GetTool("remote:missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestUpstreamLoader_GetSummaryReadThrough(t *testing.T) {
+	calls := 0
+	loader := func(id string) (toolmodel.Tool, toolmodel.ToolBackend, error) {
+		calls++
+		if id != "remote:calc" {
+			return toolmodel.Tool{}, toolmodel.ToolBackend{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		return makeTestTool("calc", "remote", "Remote calculator", []string{"math"}), makeMCPBackend("upstream"), nil
+	}
+	idx := NewInMemoryIndex(IndexOptions{UpstreamLoader: loader})
+
+	summary, err := idx.GetSummary("remote:calc")
+	if err != nil {
+		t.Fatalf("GetSummary failed: %v", err)
+	}
+	if summary.ID != "remote:calc" || summary.ShortDescription != "Remote calculator" || len(summary.Tags) != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if _, err := idx.GetSummary("remote:calc"); err != nil || calls != 1 {
+		t.Fatalf("expected cached summary after one upstream call, got %d calls, %v", calls, err)
+	}
+	if _, err := idx.GetSummary("remote:missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestUpstreamLoader_RejectsMismatchedID(t *testing.T) {
+	loader := func(string) (toolmodel.Tool, toolmodel.ToolBackend, error) {
+		return makeTestTool("other", "remote", "Wrong tool", nil), makeMCPBackend("upstream"), nil
+	}
+	idx := NewInMemoryIndex(IndexOptions{UpstreamLoader: loader})
+
+	if _, _, err := idx.GetTool("remote:calc"); !errors.Is(err, ErrInvalidTool) {
+		t.Fatalf("expected ErrInvalidTool, got %v", err)
+	}
+	if _, _, err := idx.getToolLocal("remote:other"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("mismatched tool must not be registered, got %v", err)
+	}
+}
+
+func TestUpstreamLoader_ConcurrentMissesShareOneLoad(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	loader := func(string) (toolmodel.Tool, toolmodel.ToolBackend, error) {
+		calls.Add(1)
+		<-release
+		return makeTestTool("calc", "remote", "Remote calculator", nil), makeMCPBackend("upstream"), nil
+	}
+	idx := NewInMemoryIndex(IndexOptions{UpstreamLoader: loader})
+
+	const callers = 50
+	var started, done sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		started.Add(1)
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			started.Done()
+			if _, _, err := idx.GetTool("remote:calc"); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	started.Wait()
+	// Give waiters a chance to join the in-flight load before releasing it.
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	done.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("GetTool failed: %v", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected 1 upstream call, got %d", got)
+	}
+}
+
+func TestLoadGroup_PanicReleasesWaiters(t *testing.T) {
+	var g loadGroup
+	release := make(chan struct{})
+	leaderDone := make(chan any)
+	go func() {
+		defer func() { leaderDone <- recover() }()
+		g.do("remote:calc", func() (toolmodel.Tool, toolmodel.ToolBackend, error) {
+			<-release
+			panic("loader exploded")
+		})
+	}()
+	for {
+		g.mu.Lock()
+		_, inFlight := g.calls["remote:calc"]
+		g.mu.Unlock()
+		if inFlight {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	waiterErr := make(chan error, 1)
+	go func() {
+		_, _, err := g.do("remote:calc", func() (toolmodel.Tool, toolmodel.ToolBackend, error) {
+			t.Error("waiter must share the in-flight load")
+			return toolmodel.Tool{}, toolmodel.ToolBackend{}, nil
+		})
+		waiterErr <- err
+	}()
+	// Give the waiter a chance to join the in-flight load before releasing it.
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	if r := <-leaderDone; r != "loader exploded" {
+		t.Fatalf("expected the panic to reach the leader, got %v", r)
+	}
+	select {
+	case err := <-waiterErr:
+		if err == nil {
+			t.Fatal("expected waiter to receive an error")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter blocked after the loader panicked")
+	}
+
+	// The key is released, so the next miss loads again.
+	if _, _, err := g.do("remote:calc", func() (toolmodel.Tool, toolmodel.ToolBackend, error) {
+		return toolmodel.Tool{}, toolmodel.ToolBackend{}, nil
+	}); err != nil {
+		t.Fatalf("expected a fresh load after the panic, got %v", err)
+	}
+}
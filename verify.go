@@ -0,0 +1,79 @@
+package toolindex
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// IssueKind classifies a problem reported by Verify.
+type IssueKind string
+
+const (
+	// IssueDuplicateDocText flags tools whose search doc text is identical,
+	// so the default searcher can only order them by ID.
+	IssueDuplicateDocText IssueKind = "duplicate_doc_text"
+	// IssueBackendKeyMismatch flags a tool whose backend identity index does
+	// not line up with its backend list. It indicates an internal bug.
+	IssueBackendKeyMismatch IssueKind = "backend_key_mismatch"
+)
+
+// VerifyIssue is one catalog-hygiene problem found by Verify.
+type VerifyIssue struct {
+	Kind    IssueKind
+	ToolIDs []string // affected tools, sorted
+	Detail  string
+}
+
+// Verify inspects the index for catalog-quality problems and returns them
+// ordered by kind and then first tool ID. It does not modify the index.
+func (idx *InMemoryIndex) Verify() []VerifyIssue {
+	var issues []VerifyIssue
+	idx.mu.RLock()
+	byDocText := make(map[string][]string)
+	for id, record := range idx.tools {
+		byDocText[record.docText] = append(byDocText[record.docText], id)
+		if detail := checkBackendKeys(record); detail != "" {
+			issues = append(issues, VerifyIssue{Kind: IssueBackendKeyMismatch, ToolIDs: []string{id}, Detail: detail})
+		}
+	}
+	idx.mu.RUnlock()
+
+	for docText, ids := range byDocText {
+		if len(ids) < 2 {
+			continue
+		}
+		sort.Strings(ids)
+		issues = append(issues, VerifyIssue{
+			Kind:    IssueDuplicateDocText,
+			ToolIDs: ids,
+			Detail:  "identical search doc text " + strconv.Quote(truncateRunes(docText, 60)),
+		})
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Kind != issues[j].Kind {
+			return issues[i].Kind < issues[j].Kind
+		}
+		return issues[i].ToolIDs[0] < issues[j].ToolIDs[0]
+	})
+	return issues
+}
+
+// checkBackendKeys verifies that every backendKeys entry points at the slot
+// holding the backend with that identity and that both have the same size.
+// It returns a description of the first problem, or "" if none.
+func checkBackendKeys(record *toolRecord) string {
+	if len(record.backendKeys) != len(record.backends) {
+		return fmt.Sprintf("%d backend keys for %d backends", len(record.backendKeys), len(record.backends))
+	}
+	for key, i := range record.backendKeys {
+		if i < 0 || i >= len(record.backends) {
+			return fmt.Sprintf("backend key points at slot %d of %d", i, len(record.backends))
+		}
+		if backendIdentity(record.backends[i]) != key {
+			return fmt.Sprintf("slot %d holds a different backend than its key", i)
+		}
+	}
+	return ""
+}
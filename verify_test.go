@@ -0,0 +1,97 @@
+package toolindex
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/jonwraymond/toolmodel"
+)
+
+func TestSearch_IdenticalDocTextTiesBreakByID(t *testing.T) {
+	idx := NewInMemoryIndex()
+	// Same name and description in different namespaces, registered out of order.
+	for _, ns := range []string{"zeta", "alpha", "mid"} {
+		mustRegister(t, idx, makeTestTool("helper", ns, "Generic helper", nil), makeLocalBackend(ns))
+	}
+
+	for range 5 {
+		results, err := idx.Search("generic", 10)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		var ids []string
+		for _, r := range results {
+			ids = append(ids, r.ID)
+		}
+		if !slices.Equal(ids, []string{"alpha:helper", "mid:helper", "zeta:helper"}) {
+			t.Fatalf("expected ID tie-break order, got %v", ids)
+		}
+	}
+}
+
+func TestVerify_DuplicateDocText(t *testing.T) {
+	idx := NewInMemoryIndex(IndexOptions{PreserveFieldBoundaries: true})
+	mustRegister(t, idx, makeTestTool("a", "ns", "Same", nil), makeLocalBackend("a"))
+	mustRegister(t, idx, makeTestTool("b", "ns", "Different", nil), makeLocalBackend("b"))
+	if issues := idx.Verify(); len(issues) != 0 {
+		t.Fatalf("expected no issues, got %+v", issues)
+	}
+
+	// With a custom augmenter two tools can collapse to the same doc text.
+	idx = NewInMemoryIndex(IndexOptions{
+		DocTextAugmenter: func(_ toolmodel.Tool, _ string) string { return "generic tool" },
+	})
+	mustRegister(t, idx, makeTestTool("b", "ns", "B", nil), makeLocalBackend("b"))
+	mustRegister(t, idx, makeTestTool("a", "ns", "A", nil), makeLocalBackend("a"))
+
+	issues := idx.Verify()
+	if len(issues) != 1 {
+		t.Fatalf("expected one issue, got %+v", issues)
+	}
+	if issues[0].Kind != IssueDuplicateDocText || !slices.Equal(issues[0].ToolIDs, []string{"ns:a", "ns:b"}) {
+		t.Fatalf("unexpected issue: %+v", issues[0])
+	}
+}
+
+func TestVerify_BackendKeyMismatch(t *testing.T) {
+	idx := NewInMemoryIndex()
+	tool := makeTestTool("t", "ns", "T", nil)
+	mustRegister(t, idx, tool, makeLocalBackend("a"))
+	mustRegister(t, idx, tool, makeLocalBackend("b"))
+
+	// Corrupt the record directly to simulate a bookkeeping bug.
+	record := idx.tools["ns:t"]
+	record.backends[0], record.backends[1] = record.backends[1], record.backends[0]
+
+	issues := idx.Verify()
+	if len(issues) != 1 || issues[0].Kind != IssueBackendKeyMismatch || issues[0].ToolIDs[0] != "ns:t" {
+		t.Fatalf("expected a backend key mismatch, got %+v", issues)
+	}
+}
+
+// FuzzRegisterUnregister drives random register/unregister sequences and
+// checks that backend bookkeeping stays consistent.
+func FuzzRegisterUnregister(f *testing.F) {
+	f.Add([]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9})
+	f.Add([]byte{0x10, 0x11, 0x90, 0x12, 0x91, 0x13, 0x92, 0x93})
+	f.Add([]byte{0xff, 0x00, 0x80, 0x01, 0x81, 0x02, 0x82})
+
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		idx := NewInMemoryIndex()
+		for _, op := range ops {
+			// Low bits pick the tool and backend; the high bit picks the operation.
+			name := string(rune('a' + op&0x3))
+			handler := string(rune('p' + (op>>2)&0x7))
+			if op&0x80 == 0 {
+				mustRegister(t, idx, makeTestTool(name, "ns", "desc", nil), makeLocalBackend(handler))
+			} else {
+				_ = idx.UnregisterBackend("ns:"+name, toolmodel.BackendKindLocal, handler)
+			}
+			for _, issue := range idx.Verify() {
+				if issue.Kind == IssueBackendKeyMismatch {
+					t.Fatalf("after op %#x: %+v", op, issue)
+				}
+			}
+		}
+	})
+}